@@ -0,0 +1,189 @@
+package atapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/EfimoffN/authorBot/lib/e"
+	"github.com/EfimoffN/authorBot/metrics"
+)
+
+const (
+	DefaultURL   = "https://api.author.today"
+	DefaultToken = "guest"
+
+	requestTimeout = 30 * time.Second
+	// maxPages ограничивает число страниц списка, которые загружаются за
+	// один запрос к клиенту.
+	maxPages = 50
+)
+
+var (
+	ErrNotFound  = errors.New("api: not found")
+	ErrForbidden = errors.New("api: access forbidden")
+)
+
+// Client обращается к JSON API, которым пользуются мобильные приложения
+// author.today.
+type Client struct {
+	baseURL string
+	token   string
+	client  *http.Client
+}
+
+func NewClient(baseURL, token string) *Client {
+	if baseURL == "" {
+		baseURL = DefaultURL
+	}
+
+	if token == "" {
+		token = DefaultToken
+	}
+
+	return &Client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		token:   token,
+		client:  &http.Client{Timeout: requestTimeout},
+	}
+}
+
+func (c *Client) Work(ctx context.Context, workID string) (*Work, error) {
+	work := &Work{}
+
+	if err := c.get(ctx, "/v1/work/"+url.PathEscape(workID)+"/details", work); err != nil {
+		return nil, e.Wrap("get work failed with an error: ", err)
+	}
+
+	return work, nil
+}
+
+func (c *Client) Chapters(ctx context.Context, workID string) ([]Chapter, error) {
+	chapters := []Chapter{}
+
+	if err := c.get(ctx, "/v1/work/"+url.PathEscape(workID)+"/content", &chapters); err != nil {
+		return nil, e.Wrap("get chapters failed with an error: ", err)
+	}
+
+	return chapters, nil
+}
+
+func (c *Client) Author(ctx context.Context, login string) (*Author, error) {
+	author := &Author{}
+
+	if err := c.get(ctx, "/v1/author/"+url.PathEscape(login), author); err != nil {
+		return nil, e.Wrap("get author failed with an error: ", err)
+	}
+
+	return author, nil
+}
+
+// AuthorWorks загружает все страницы списка произведений автора.
+func (c *Client) AuthorWorks(ctx context.Context, login string) (*WorkList, error) {
+	works := &WorkList{}
+
+	for page := 1; page <= maxPages; page++ {
+		list := &WorkList{}
+
+		if err := c.get(ctx, pagePath("/v1/work/user/"+url.PathEscape(login), page), list); err != nil {
+			return nil, e.Wrap("get author works failed with an error: ", err)
+		}
+
+		works.TotalCount = list.TotalCount
+		works.Items = append(works.Items, list.Items...)
+
+		if len(list.Items) == 0 || len(works.Items) >= works.TotalCount {
+			break
+		}
+	}
+
+	return works, nil
+}
+
+// AuthorPosts загружает все страницы списка записей в блоге автора.
+func (c *Client) AuthorPosts(ctx context.Context, login string) (*PostList, error) {
+	posts := &PostList{}
+
+	for page := 1; page <= maxPages; page++ {
+		list := &PostList{}
+
+		if err := c.get(ctx, pagePath("/v1/post/user/"+url.PathEscape(login), page), list); err != nil {
+			return nil, e.Wrap("get author posts failed with an error: ", err)
+		}
+
+		posts.TotalCount = list.TotalCount
+		posts.Items = append(posts.Items, list.Items...)
+
+		if len(list.Items) == 0 || len(posts.Items) >= posts.TotalCount {
+			break
+		}
+	}
+
+	return posts, nil
+}
+
+// PostComments возвращает первую страницу комментариев к записи блога.
+func (c *Client) PostComments(ctx context.Context, postID string) (*CommentList, error) {
+	comments := &CommentList{}
+
+	if err := c.get(ctx, "/v1/post/"+url.PathEscape(postID)+"/comments", comments); err != nil {
+		return nil, e.Wrap("get post comments failed with an error: ", err)
+	}
+
+	return comments, nil
+}
+
+func (c *Client) Series(ctx context.Context, seriesID string) (*Series, error) {
+	series := &Series{}
+
+	if err := c.get(ctx, "/v1/series/"+url.PathEscape(seriesID), series); err != nil {
+		return nil, e.Wrap("get series failed with an error: ", err)
+	}
+
+	return series, nil
+}
+
+// pagePath добавляет к пути списка номер страницы, начиная с единицы.
+func pagePath(path string, page int) string {
+	return path + "?page=" + strconv.Itoa(page)
+}
+
+func (c *Client) get(ctx context.Context, path string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return e.Wrap("new request failed with an error: ", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.client.Do(req)
+	metrics.ObserveFetch(metrics.ClientAPI, resp, err)
+
+	if err != nil {
+		return e.Wrap("do request failed with an error: ", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return e.Wrap(path, ErrNotFound)
+	case http.StatusForbidden, http.StatusUnauthorized:
+		return e.Wrap(path, ErrForbidden)
+	default:
+		return fmt.Errorf("unexpected status code %d for %s", resp.StatusCode, path)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return e.Wrap("decode response failed with an error: ", err)
+	}
+
+	return nil
+}
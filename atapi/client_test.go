@@ -0,0 +1,191 @@
+package atapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+// newStubServer отдает записанные ответы API из testdata.
+func newStubServer(t *testing.T) *httptest.Server {
+	routes := map[string]string{
+		"/v1/work/101/details":       "testdata/work_details.json",
+		"/v1/work/101/content":       "testdata/work_content.json",
+		"/v1/author/login":           "testdata/author.json",
+		"/v1/work/user/login?page=1": "testdata/author_works.json",
+		"/v1/work/user/login?page=2": "testdata/author_works_2.json",
+		"/v1/post/user/login?page=1": "testdata/author_posts.json",
+		"/v1/post/9001/comments":     "testdata/post_comments.json",
+		"/v1/series/7":               "testdata/series.json",
+		"/v1/work/403/details":       "",
+		"/v1/work/500/details":       "",
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+DefaultToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/v1/work/403/details":
+			w.WriteHeader(http.StatusForbidden)
+			return
+		case "/v1/work/500/details":
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		file, ok := routes[r.URL.RequestURI()]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Errorf("read test data: %s", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+	}))
+}
+
+func Test_Work(t *testing.T) {
+	srv := newStubServer(t)
+	defer srv.Close()
+
+	c := NewClient(srv.URL, "")
+
+	work, err := c.Work(context.Background(), "101")
+	if err != nil {
+		t.Fatalf("Work() error = %v", err)
+	}
+
+	if work.Title != "Первая книга" || work.Status != WorkInProgress || work.Price != 150 || work.Discount != 25 || work.SeriesID != 7 {
+		t.Errorf("Work() = %+v", work)
+	}
+
+	if !work.UpdateTime.Equal(time.Date(2022, 8, 1, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("Work() UpdateTime = %v", work.UpdateTime)
+	}
+}
+
+func Test_Chapters(t *testing.T) {
+	srv := newStubServer(t)
+	defer srv.Close()
+
+	c := NewClient(srv.URL, "")
+
+	chapters, err := c.Chapters(context.Background(), "101")
+	if err != nil {
+		t.Fatalf("Chapters() error = %v", err)
+	}
+
+	if len(chapters) != 3 || chapters[1].Title != "Глава 2" || !chapters[2].IsDraft {
+		t.Errorf("Chapters() = %+v", chapters)
+	}
+}
+
+func Test_Author(t *testing.T) {
+	srv := newStubServer(t)
+	defer srv.Close()
+
+	c := NewClient(srv.URL, "")
+
+	author, err := c.Author(context.Background(), "login")
+	if err != nil {
+		t.Fatalf("Author() error = %v", err)
+	}
+
+	if author.FIO != "Иван Автор" || author.UserName != "login" {
+		t.Errorf("Author() = %+v", author)
+	}
+
+	works, err := c.AuthorWorks(context.Background(), "login")
+	if err != nil {
+		t.Fatalf("AuthorWorks() error = %v", err)
+	}
+
+	if works.TotalCount != 3 || len(works.Items) != 3 || works.Items[0].SeriesTitle != "Большой цикл" || works.Items[2].ID != 50 {
+		t.Errorf("AuthorWorks() = %+v", works)
+	}
+
+	posts, err := c.AuthorPosts(context.Background(), "login")
+	if err != nil {
+		t.Fatalf("AuthorPosts() error = %v", err)
+	}
+
+	if len(posts.Items) != 1 || posts.Items[0].ID != 9001 || posts.Items[0].CommentCount != 4 {
+		t.Errorf("AuthorPosts() = %+v", posts)
+	}
+}
+
+func Test_PostComments(t *testing.T) {
+	srv := newStubServer(t)
+	defer srv.Close()
+
+	c := NewClient(srv.URL, "")
+
+	comments, err := c.PostComments(context.Background(), "9001")
+	if err != nil {
+		t.Fatalf("PostComments() error = %v", err)
+	}
+
+	if comments.TotalCount != 4 || len(comments.Items) != 4 || comments.Items[3].Text != "Когда продолжение?" {
+		t.Errorf("PostComments() = %+v", comments)
+	}
+}
+
+func Test_Series(t *testing.T) {
+	srv := newStubServer(t)
+	defer srv.Close()
+
+	c := NewClient(srv.URL, "")
+
+	series, err := c.Series(context.Background(), "7")
+	if err != nil {
+		t.Fatalf("Series() error = %v", err)
+	}
+
+	if series.Title != "Большой цикл" || len(series.Works) != 2 || series.Works[1].Status != WorkFrozen {
+		t.Errorf("Series() = %+v", series)
+	}
+}
+
+func Test_Errors(t *testing.T) {
+	srv := newStubServer(t)
+	defer srv.Close()
+
+	tests := []struct {
+		name    string
+		client  *Client
+		workID  string
+		wantErr error
+	}{
+		{name: "not found", client: NewClient(srv.URL, ""), workID: "404", wantErr: ErrNotFound},
+		{name: "forbidden", client: NewClient(srv.URL, ""), workID: "403", wantErr: ErrForbidden},
+		{name: "bad token", client: NewClient(srv.URL, "wrong"), workID: "101", wantErr: ErrForbidden},
+		{name: "server error", client: NewClient(srv.URL, ""), workID: "500", wantErr: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.client.Work(context.Background(), tt.workID)
+			if err == nil {
+				t.Fatalf("Work() error = nil, want error")
+			}
+
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("Work() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
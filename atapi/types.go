@@ -0,0 +1,84 @@
+package atapi
+
+import "time"
+
+// Статусы произведения в ответах API.
+const (
+	WorkInProgress = "InProgress"
+	WorkCompleted  = "Completed"
+	WorkFrozen     = "Frozen"
+)
+
+// Work ...
+type Work struct {
+	ID          int       `json:"id"`
+	Title       string    `json:"title"`
+	Status      string    `json:"status"`
+	IsDraft     bool      `json:"isDraft"`
+	Price       float64   `json:"price"`
+	Discount    float64   `json:"discount"`
+	IsFree      bool      `json:"isFree"`
+	SeriesID    int       `json:"seriesId"`
+	SeriesTitle string    `json:"seriesTitle"`
+	SeriesOrder int       `json:"seriesOrder"`
+	AuthorLogin string    `json:"authorUserName"`
+	AuthorName  string    `json:"authorFIO"`
+	UpdateTime  time.Time `json:"lastModificationTime"`
+	Annotation  string    `json:"annotation"`
+}
+
+// Chapter ...
+type Chapter struct {
+	ID          int       `json:"id"`
+	Title       string    `json:"title"`
+	IsDraft     bool      `json:"isDraft"`
+	PublishTime time.Time `json:"publishTime"`
+}
+
+// Author ...
+type Author struct {
+	ID       int    `json:"id"`
+	UserName string `json:"userName"`
+	FIO      string `json:"fio"`
+}
+
+// WorkList ...
+type WorkList struct {
+	TotalCount int    `json:"totalCount"`
+	Items      []Work `json:"searchResults"`
+}
+
+// Series ...
+type Series struct {
+	ID    int    `json:"id"`
+	Title string `json:"title"`
+	Works []Work `json:"works"`
+}
+
+// Post ...
+type Post struct {
+	ID           int       `json:"id"`
+	Title        string    `json:"title"`
+	Text         string    `json:"text"`
+	CreationTime time.Time `json:"creationTime"`
+	CommentCount int       `json:"commentCount"`
+}
+
+// PostList ...
+type PostList struct {
+	TotalCount int    `json:"totalCount"`
+	Items      []Post `json:"items"`
+}
+
+// Comment ...
+type Comment struct {
+	ID           int       `json:"id"`
+	Text         string    `json:"text"`
+	CreationTime time.Time `json:"creationTime"`
+}
+
+// CommentList ...
+type CommentList struct {
+	TotalCount int       `json:"totalCount"`
+	Items      []Comment `json:"items"`
+}
@@ -0,0 +1,219 @@
+package commands
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/EfimoffN/authorBot/events"
+	"github.com/EfimoffN/authorBot/lib/e"
+	"github.com/EfimoffN/authorBot/lib/logger"
+	"github.com/EfimoffN/authorBot/metrics"
+	"github.com/EfimoffN/authorBot/sources"
+	"github.com/EfimoffN/authorBot/sqlapi"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+const (
+	StatsCmd     = "/stats"
+	BroadcastCmd = "/broadcast"
+	BanCmd       = "/ban"
+	UnbanCmd     = "/unban"
+	CheckNowCmd  = "/checknow"
+)
+
+// adminCmds — команды администраторов бота. Остальным пользователям бот
+// отвечает на них как на неизвестные команды.
+var adminCmds = map[string]bool{
+	StatsCmd:     true,
+	BroadcastCmd: true,
+	BanCmd:       true,
+	UnbanCmd:     true,
+	CheckNowCmd:  true,
+}
+
+// broadcastReport — как часто обновляется сообщение о ходе рассылки.
+const broadcastReport = 5 * time.Second
+
+// isBotAdmin сообщает, входит ли пользователь в список администраторов
+// бота из конфигурации.
+func (c *Commands) isBotAdmin(from *tgbotapi.User) bool {
+	if from == nil {
+		return false
+	}
+
+	for _, id := range c.settings.get().AdminIDs {
+		if id == from.ID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isBanned сообщает, заблокирован ли отправитель или чат, из которого
+// пришло сообщение.
+func (c *Commands) isBanned(chat *tgbotapi.Chat, from *tgbotapi.User) (bool, error) {
+	ids := []int{ownerID(chat)}
+	if from != nil && from.ID != ownerID(chat) {
+		ids = append(ids, from.ID)
+	}
+
+	for _, id := range ids {
+		userRow, err := c.Event.GetUser(id)
+		if err != nil {
+			return false, e.Wrap("get user failed with an error: ", err)
+		}
+
+		if userRow != nil && userRow.Banned {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (c *Commands) doAdminCommand(message *tgbotapi.Message, cmd string, args []string) error {
+	switch cmd {
+	case StatsCmd:
+		return c.sendStats(message)
+	case BroadcastCmd:
+		return c.broadcast(message, strings.TrimSpace(message.CommandArguments()))
+	case BanCmd:
+		return c.setBanned(message, args, true)
+	case UnbanCmd:
+		return c.setBanned(message, args, false)
+	case CheckNowCmd:
+		return c.checkNow(message, args)
+	default:
+		return c.sendUnknownCommand(message)
+	}
+}
+
+// sendStats отправляет число пользователей, ссылок и подписок, а также
+// проверок и ошибок за последний час.
+func (c *Commands) sendStats(message *tgbotapi.Message) error {
+	statsRow, err := c.Event.GetStats()
+	if err != nil {
+		return e.Wrap("get stats failed with an error: ", err)
+	}
+
+	checks := c.Watcher.Stats()
+
+	return c.sendText(message, msgStats, statsRow.Users, statsRow.Banned, statsRow.Blocked, statsRow.Links, statsRow.Subscriptions, checks.Checks, checks.Failures)
+}
+
+// broadcast рассылает текст всем незаблокированным пользователям. Рассылка
+// идет в фоне, а ход ее виден в обновляемом сообщении.
+func (c *Commands) broadcast(message *tgbotapi.Message, text string) error {
+	if text == "" {
+		return c.sendText(message, msgBroadcastUsage)
+	}
+
+	userRows, err := c.Event.GetActiveUsers()
+	if err != nil {
+		return e.Wrap("get active users failed with an error: ", err)
+	}
+
+	l := c.locale(message.Chat, message.From)
+
+	progress, err := c.BotAPI.Send(tgbotapi.NewMessage(message.Chat.ID, l.T(msgBroadcastProgress, 0, len(userRows))))
+	if err != nil {
+		return e.Wrap("Sending the message failed with an error: ", err)
+	}
+
+	go c.fanOut(progress, l, text, userRows, c.settings.get().BroadcastRate)
+
+	return nil
+}
+
+// fanOut отправляет текст пользователям не быстрее rate сообщений в секунду.
+// Сообщения уходят в чаты владельцев подписок, а не в каналы.
+func (c *Commands) fanOut(progress tgbotapi.Message, l locale, text string, userRows []*sqlapi.UserRow, rate int) {
+	ticker := time.NewTicker(time.Second / time.Duration(rate))
+	defer ticker.Stop()
+
+	sent, failed := 0, 0
+	reported := time.Now()
+
+	queue := metrics.QueueDepth.WithLabelValues(metrics.QueueBroadcast)
+	defer queue.Set(0)
+
+	for i, u := range userRows {
+		queue.Set(float64(len(userRows) - i))
+
+		select {
+		case <-c.CTX.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if _, err := c.BotAPI.Send(tgbotapi.NewMessage(int64(u.UserID), text)); err != nil {
+			c.Log.Warn("broadcast message", "recipient_id", u.UserID, logger.Err(err))
+			failed++
+		} else {
+			sent++
+		}
+
+		if time.Since(reported) >= broadcastReport {
+			c.editProgress(progress, l.T(msgBroadcastProgress, i+1, len(userRows)))
+			reported = time.Now()
+		}
+	}
+
+	c.editProgress(progress, l.T(msgBroadcastDone, sent, failed))
+}
+
+func (c *Commands) editProgress(progress tgbotapi.Message, text string) {
+	edit := tgbotapi.NewEditMessageText(progress.Chat.ID, progress.MessageID, text)
+	if _, err := c.BotAPI.Send(edit); err != nil {
+		c.Log.Warn("broadcast progress", logger.Err(err))
+	}
+}
+
+// setBanned блокирует пользователя по идентификатору или снимает блокировку.
+func (c *Commands) setBanned(message *tgbotapi.Message, args []string, banned bool) error {
+	if len(args) != 1 {
+		return c.sendText(message, msgBanUsage)
+	}
+
+	userID, err := strconv.Atoi(args[0])
+	if err != nil {
+		return c.sendText(message, msgBanUsage)
+	}
+
+	err = c.Event.SetBanned(userID, banned)
+	if err != nil && !errors.Is(err, events.ErrUserNotFound) {
+		return e.Wrap("set banned failed with an error: ", err)
+	}
+
+	switch {
+	case err != nil:
+		return c.sendText(message, msgUserNotFound)
+	case banned:
+		return c.sendText(message, msgBanned, userID)
+	}
+
+	return c.sendText(message, msgUnbanned, userID)
+}
+
+// checkNow сразу проверяет ссылку и рассылает оповещения об изменениях.
+func (c *Commands) checkNow(message *tgbotapi.Message, args []string) error {
+	if len(args) != 1 || !isURL(args[0]) {
+		return c.sendText(message, msgCheckUsage)
+	}
+
+	err := c.Watcher.CheckNow(args[0])
+
+	switch {
+	case err == nil:
+		return c.sendText(message, msgCheckDone)
+	case errors.Is(err, events.ErrLinkNotDB), errors.Is(err, sources.ErrUnsupported), errors.Is(err, sources.ErrUnsupportedLink):
+		return c.sendText(message, msgLinkNotTracked)
+	}
+
+	c.Log.Warn("check now", logger.KeyLink, args[0], logger.Err(err))
+
+	return c.sendText(message, msgCheckFailed, err.Error())
+}
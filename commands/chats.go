@@ -0,0 +1,101 @@
+package commands
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/EfimoffN/authorBot/lib/e"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+// channelOff возвращает оповещения из канала в чат владельца подписок.
+const channelOff = "off"
+
+// ownerID возвращает владельца подписок, которыми управляют из чата. В
+// личном чате это пользователь: идентификатор такого чата совпадает с
+// идентификатором пользователя. В группе это сама группа, ее подписки
+// общие для всех участников.
+func ownerID(chat *tgbotapi.Chat) int {
+	return int(chat.ID)
+}
+
+// isChatAdmin сообщает, является ли пользователь создателем или
+// администратором чата.
+func (c *Commands) isChatAdmin(chatID int64, userID int) (bool, error) {
+	member, err := c.BotAPI.GetChatMember(tgbotapi.ChatConfigWithUser{ChatID: chatID, UserID: userID})
+	if err != nil {
+		return false, e.Wrap("get chat member failed with an error: ", err)
+	}
+
+	return member.IsCreator() || member.IsAdministrator(), nil
+}
+
+// migrateChat переносит подписки группы на супергруппу, в которую она
+// превратилась. Telegram сообщает об этом служебным сообщением в старой
+// группе.
+func (c *Commands) migrateChat(message *tgbotapi.Message) error {
+	if err := c.Event.MigrateChat(message.Chat.ID, message.MigrateToChatID); err != nil {
+		return e.Wrap("migrate chat failed with an error: ", err)
+	}
+
+	return nil
+}
+
+// setChannel направляет оповещения в канал, где администраторами являются
+// и пользователь, и бот, или возвращает их обратно в чат.
+func (c *Commands) setChannel(message *tgbotapi.Message, args []string) error {
+	if len(args) != 1 {
+		return c.sendText(message, msgChannelUsage)
+	}
+
+	if err := c.addNewUser(message); err != nil {
+		return e.Wrap("save new user failed with an error: ", err)
+	}
+
+	if args[0] == channelOff {
+		if err := c.Event.SetChatTarget(ownerID(message.Chat), message.Chat.ID); err != nil {
+			return e.Wrap("set chat target failed with an error: ", err)
+		}
+
+		return c.sendText(message, msgChannelOff)
+	}
+
+	channel, ok := c.getChannel(args[0])
+	if !ok {
+		return c.sendText(message, msgChannelNotFound)
+	}
+
+	admin, err := c.isChatAdmin(channel.ID, message.From.ID)
+	if err != nil || !admin {
+		return c.sendText(message, msgChannelNotAdmin)
+	}
+
+	bot, err := c.BotAPI.GetChatMember(tgbotapi.ChatConfigWithUser{ChatID: channel.ID, UserID: c.BotAPI.Self.ID})
+	if err != nil || !bot.CanPostMessages {
+		return c.sendText(message, msgChannelNoBot)
+	}
+
+	if err := c.Event.SetChatTarget(ownerID(message.Chat), channel.ID); err != nil {
+		return e.Wrap("set chat target failed with an error: ", err)
+	}
+
+	return c.sendText(message, msgChannelSet)
+}
+
+// getChannel ищет канал по имени вида @name или по идентификатору.
+func (c *Commands) getChannel(name string) (tgbotapi.Chat, bool) {
+	config := tgbotapi.ChatConfig{SuperGroupUsername: name}
+
+	if id, err := strconv.ParseInt(name, 10, 64); err == nil {
+		config = tgbotapi.ChatConfig{ChatID: id}
+	} else if !strings.HasPrefix(name, "@") {
+		config.SuperGroupUsername = "@" + name
+	}
+
+	chat, err := c.BotAPI.GetChat(config)
+	if err != nil || !chat.IsChannel() {
+		return tgbotapi.Chat{}, false
+	}
+
+	return chat, true
+}
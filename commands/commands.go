@@ -3,147 +3,564 @@ package commands
 import (
 	"context"
 	"errors"
+	"fmt"
+	"log/slog"
 	"net/url"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/EfimoffN/authorBot/events"
 	"github.com/EfimoffN/authorBot/lib/e"
+	"github.com/EfimoffN/authorBot/lib/logger"
+	"github.com/EfimoffN/authorBot/lib/tz"
+	"github.com/EfimoffN/authorBot/metrics"
+	"github.com/EfimoffN/authorBot/render"
+	"github.com/EfimoffN/authorBot/sources"
+	"github.com/EfimoffN/authorBot/sqlapi"
+	"github.com/EfimoffN/authorBot/watcher"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
 )
 
 const (
-	RmvCmd     = "/rmv"
-	HelpCmd    = "/help"
-	StartCmd   = "/start"
-	AllLinkCmd = "/all"
+	RmvCmd        = "/rmv"
+	HelpCmd       = "/help"
+	StartCmd      = "/start"
+	AllLinkCmd    = "/all"
+	PriceCmd      = "/price"
+	NoPriceCmd    = "/noprice"
+	StatusCmd     = "/status"
+	NoStatusCmd   = "/nostatus"
+	ChaptersCmd   = "/chapters"
+	NoChaptersCmd = "/nochapters"
+	DigestCmd     = "/digest"
+	QuietCmd      = "/quiet"
+	TimezoneCmd   = "/tz"
+	FilterCmd     = "/filter"
+	IncludeCmd    = "/include"
+	ExcludeCmd    = "/exclude"
+	PauseCmd      = "/pause"
+	ResumeCmd     = "/resume"
+	SnoozeCmd     = "/snooze"
+	StopCmd       = "/stop"
+	AddCmd        = "/add"
+	ChannelCmd    = "/channel"
+	LangCmd       = "/lang"
+	ExportCmd     = "/export"
+	ImportCmd     = "/import"
 )
 
+// knownCmds — команды, которые учитываются в метриках под своим именем.
+var knownCmds = map[string]bool{
+	RmvCmd: true, HelpCmd: true, StartCmd: true, AllLinkCmd: true,
+	PriceCmd: true, NoPriceCmd: true, StatusCmd: true, NoStatusCmd: true,
+	ChaptersCmd: true, NoChaptersCmd: true, DigestCmd: true, QuietCmd: true,
+	TimezoneCmd: true, FilterCmd: true, IncludeCmd: true, ExcludeCmd: true,
+	PauseCmd: true, ResumeCmd: true, SnoozeCmd: true, StopCmd: true,
+	AddCmd: true, ChannelCmd: true, LangCmd: true, ExportCmd: true,
+	ImportCmd: true, StatsCmd: true, BroadcastCmd: true, BanCmd: true,
+	UnbanCmd: true, CheckNowCmd: true,
+}
+
+// Метки метрик для сообщений без команды.
+const (
+	labelCallback = "callback"
+	labelDocument = "document"
+	labelLink     = "link"
+	labelRemove   = "remove"
+	labelMigrate  = "migrate"
+	labelUnknown  = "unknown"
+	labelText     = "text"
+)
+
+// readOnlyCmds — команды, которые в группе доступны всем участникам.
+// Остальные меняют общие подписки группы и доступны только ее
+// администраторам.
+var readOnlyCmds = map[string]bool{
+	StartCmd:   true,
+	HelpCmd:    true,
+	AllLinkCmd: true,
+	ExportCmd:  true,
+}
+
+// defaultDigestHour — час отправки сводки, если пользователь его не указал.
+const defaultDigestHour = 9
+
 type Commands struct {
-	BotAPI *tgbotapi.BotAPI
-	Event  events.IEvent
-	CTX    context.Context
+	BotAPI  *tgbotapi.BotAPI
+	Event   events.IEvent
+	Watcher *watcher.Watcher
+	Log     *slog.Logger
+	CTX     context.Context
+	// settings — настройки, которые меняются без перезапуска бота.
+	settings *settingsStore
 }
 
-func NewBotCommands(botAPI *tgbotapi.BotAPI, event events.IEvent, ctx context.Context) *Commands {
+func NewBotCommands(botAPI *tgbotapi.BotAPI, event events.IEvent, watcher *watcher.Watcher, settings Settings, log *slog.Logger, ctx context.Context) *Commands {
 	return &Commands{
-		BotAPI: botAPI,
-		Event:  event,
-		CTX:    ctx,
+		BotAPI:   botAPI,
+		Event:    event,
+		Watcher:  watcher,
+		Log:      log,
+		CTX:      ctx,
+		settings: newSettingsStore(settings),
 	}
 }
 
+// WithLogger возвращает копию Commands, которая вместе со слоем событий
+// пишет в журнал log. Так в записи попадают поля обрабатываемого
+// обновления.
+func (c *Commands) WithLogger(log *slog.Logger) *Commands {
+	scoped := *c
+	scoped.Log = log
+	scoped.Event = c.Event.WithLogger(log)
+
+	return &scoped
+}
+
+// MessageFields возвращает поля журнала для сообщения: отправителя, чат и
+// команду.
+func MessageFields(message *tgbotapi.Message) []interface{} {
+	fields := []interface{}{logger.KeyChatID, message.Chat.ID, logger.KeyCommand, commandLabel(message)}
+	if message.From != nil {
+		fields = append(fields, logger.KeyUserID, message.From.ID)
+	}
+
+	return fields
+}
+
+// CallbackFields возвращает поля журнала для нажатия на инлайн-кнопку.
+func CallbackFields(query *tgbotapi.CallbackQuery) []interface{} {
+	fields := []interface{}{logger.KeyUserID, query.From.ID, logger.KeyCommand, labelCallback}
+	if query.Message != nil {
+		fields = append(fields, logger.KeyChatID, query.Message.Chat.ID)
+	}
+
+	return fields
+}
+
+// DoCommand обрабатывает сообщение и учитывает его в метриках.
 func (c *Commands) DoCommand(message *tgbotapi.Message) error {
+	started := time.Now()
+	err := c.doCommand(message)
+	metrics.ObserveUpdate(commandLabel(message), started, err)
+
+	return err
+}
+
+func (c *Commands) doCommand(message *tgbotapi.Message) error {
+	if message.MigrateToChatID != 0 {
+		return c.migrateChat(message)
+	}
+
 	text := strings.TrimSpace(message.Text)
 
+	cmd, args := splitCommand(text)
+
+	cmd, mention := splitMention(cmd)
+	if mention != "" && !strings.EqualFold(mention, c.BotAPI.Self.UserName) {
+		return nil
+	}
+
+	banned, err := c.isBanned(message.Chat, message.From)
+	if err != nil {
+		return e.Wrap("check banned failed with an error: ", err)
+	}
+
+	if banned {
+		return nil
+	}
+
+	if adminCmds[cmd] && c.isBotAdmin(message.From) {
+		return c.doAdminCommand(message, cmd, args)
+	}
+
+	links := []string{}
 	if isAddCmd(text) {
-		return c.saveLink(message)
+		links = extractLinks(message)
+	}
+
+	if !message.Chat.IsPrivate() {
+		// В группе бот отвечает только на команды: ссылки добавляются
+		// командой /add, а файл загружается с подписью /import. Ссылки и
+		// файлы из обычной переписки участников не обрабатываются.
+		if message.Document != nil {
+			if !c.isImportCaption(message.Caption) {
+				return nil
+			}
+		} else if !strings.HasPrefix(text, "/") {
+			return nil
+		}
+
+		if !readOnlyCmds[cmd] {
+			admin, err := c.isChatAdmin(message.Chat.ID, message.From.ID)
+			if err != nil {
+				return e.Wrap("check chat admin failed with an error: ", err)
+			}
+
+			if !admin {
+				return c.sendText(message, msgAdminsOnly)
+			}
+		}
+	}
+
+	if message.Document != nil {
+		return c.importLinks(message)
 	}
 
 	if isRemoveLink(text) {
-		return c.removeLink(message)
+		return c.removeLink(message, strings.TrimSpace(text[2:]))
+	}
+
+	if len(links) > 0 {
+		return c.saveLinks(message, links)
 	}
 
-	switch text {
+	switch cmd {
+	case AddCmd:
+		return c.addLinks(message)
+	case RmvCmd:
+		if len(args) != 1 || !isURL(args[0]) {
+			return c.sendText(message, msgRemoveUsage)
+		}
+
+		return c.removeLink(message, args[0])
+	case PriceCmd:
+		return c.setPriceAlert(message, args, true)
+	case NoPriceCmd:
+		return c.setPriceAlert(message, args, false)
+	case StatusCmd:
+		return c.setAlert(message, args, c.Event.SetStatusAlert, true, msgStatusOn)
+	case NoStatusCmd:
+		return c.setAlert(message, args, c.Event.SetStatusAlert, false, msgStatusOff)
+	case ChaptersCmd:
+		return c.setAlert(message, args, c.Event.SetChapterAlert, true, msgChaptersOn)
+	case NoChaptersCmd:
+		return c.setAlert(message, args, c.Event.SetChapterAlert, false, msgChaptersOff)
+	case DigestCmd:
+		return c.setDelivery(message, args)
+	case QuietCmd:
+		return c.setQuietHours(message, args)
+	case TimezoneCmd:
+		return c.setTimezone(message, args)
+	case FilterCmd:
+		return c.sendFilterMenu(message, args)
+	case IncludeCmd:
+		return c.setKeywords(message, args, true)
+	case ExcludeCmd:
+		return c.setKeywords(message, args, false)
+	case PauseCmd:
+		return c.pauseLink(message, args, true)
+	case ResumeCmd:
+		return c.pauseLink(message, args, false)
+	case SnoozeCmd:
+		return c.snooze(message, args)
+	case ExportCmd:
+		return c.exportLinks(message, args)
+	case ChannelCmd:
+		return c.setChannel(message, args)
+	case LangCmd:
+		return c.setLanguage(message, args)
+	}
+
+	if len(args) > 0 {
+		return c.sendUnknownCommand(message)
+	}
+
+	switch cmd {
 	case StartCmd:
 		return c.sendStart(message)
 	case HelpCmd:
 		return c.sendHelp(message)
 	case AllLinkCmd:
 		return c.getAllLinks(message)
+	case StopCmd:
+		return c.askStop(message)
+	case ImportCmd:
+		return c.sendText(message, msgImportUsage)
 	default:
 		return c.sendUnknownCommand(message)
 	}
 }
 
 func (c *Commands) sendHelp(message *tgbotapi.Message) error {
-	m := tgbotapi.NewMessage(message.Chat.ID, msgHelp)
-	replyKeyboardHide := tgbotapi.ReplyKeyboardHide{HideKeyboard: true}
-	m.ReplyMarkup = replyKeyboardHide
-	_, err := c.BotAPI.Send(m)
+	return c.sendText(message, msgHelp)
+}
+
+func (c *Commands) sendStart(message *tgbotapi.Message) error {
+	err := c.addNewUser(message)
 	if err != nil {
-		return e.Wrap("Sending the message failed with an error: ", err)
+		return e.Wrap("save new user failed with an error: ", err)
+	}
+
+	return c.sendText(message, msgHello)
+}
+
+func (c *Commands) sendUnknownCommand(message *tgbotapi.Message) error {
+	return c.sendText(message, msgUnknownCommand)
+}
+
+func (c *Commands) addNewUser(message *tgbotapi.Message) error {
+	name := message.From.UserName
+	if !message.Chat.IsPrivate() {
+		name = message.Chat.Title
+	}
+
+	err := c.Event.AddNewUser(name, ownerID(message.Chat), message.Chat.ID)
+
+	if err != nil && !errors.Is(err, events.ErrUserAlreadyAdded) {
+		return e.Wrap("save new user failed with an error: ", err)
 	}
 
 	return nil
 }
 
-func (c *Commands) sendStart(message *tgbotapi.Message) error {
-	err := c.addNewUser(message)
+// addLinks сохраняет ссылки, присланные командой /add. В группах это
+// единственный способ добавить ссылку, если боту не видна вся переписка.
+func (c *Commands) addLinks(message *tgbotapi.Message) error {
+	links := extractLinks(message)
+	if len(links) == 0 {
+		return c.sendText(message, msgAddUsage)
+	}
+
+	return c.saveLinks(message, links)
+}
+
+// saveLinks подписывает пользователя на ссылки из сообщения. На одну
+// ссылку отвечает как раньше, на несколько — сводкой по каждой ссылке.
+func (c *Commands) saveLinks(message *tgbotapi.Message, links []string) error {
+	l := c.locale(message.Chat, message.From)
+
+	if len(links) == 1 {
+		msg, err := c.saveLink(l, ownerID(message.Chat), links[0])
+		if err != nil {
+			return err
+		}
+
+		return c.send(message, msg)
+	}
+
+	msg := l.T(msgBulkHeader, len(links))
+
+	for _, link := range links {
+		status, err := c.saveLink(l, ownerID(message.Chat), link)
+		if err != nil {
+			return err
+		}
+
+		msg = msg + fmt.Sprintf(msgBulkItem, render.Escape(link), status)
+	}
+
+	return c.send(message, msg)
+}
+
+// saveLink подписывает пользователя на ссылку и возвращает текст ответа
+// о результате. Непредвиденная ошибка попадает в журнал и в ответ, чтобы не
+// прерывать обработку остальных ссылок; ошибка возвращается, только если
+// отменен контекст.
+func (c *Commands) saveLink(l locale, userID int, link string) (string, error) {
+	err := c.Event.AddNewRefUserLink(userID, link)
+	msg := l.T(msgLinkSaved)
+
+	var unsupported *sources.UnsupportedError
+
+	switch {
+	case err == nil:
+	case errors.Is(err, events.ErrLinkAlreadyExists):
+		msg = l.T(msgLinkExists)
+	case errors.As(err, &unsupported):
+		msg = l.HTML(msgUnsupportedSource, strings.Join(unsupported.Supported, ", "))
+	case errors.Is(err, sources.ErrUnsupportedLink):
+		msg = l.T(msgUnsupportedLink)
+	case c.CTX.Err() != nil:
+		return "", e.Wrap("save link failed with an error: ", err)
+	default:
+		c.Log.Error("save link", logger.KeyLink, link, logger.Err(err))
+		msg = l.T(msgLinkFailed)
+	}
+
+	return msg, nil
+}
+
+func (c *Commands) removeLink(message *tgbotapi.Message, link string) error {
+	err := c.Event.RemoveRefUserLink(ownerID(message.Chat), link)
 	if err != nil {
-		return e.Wrap("save new user failed with an error: ", err)
+		return e.Wrap("remove link failed with an error: ", err)
 	}
 
-	m := tgbotapi.NewMessage(message.Chat.ID, msgHello)
-	replyKeyboardHide := tgbotapi.ReplyKeyboardHide{HideKeyboard: true}
-	m.ReplyMarkup = replyKeyboardHide
-	_, err = c.BotAPI.Send(m)
+	return c.sendText(message, msgLinkRemoved)
+}
+
+func (c *Commands) getAllLinks(message *tgbotapi.Message) error {
+	linkRows, err := c.Event.GetAllUserLinks(ownerID(message.Chat))
 	if err != nil {
-		return e.Wrap("Sending the message failed with an error: ", err)
+		return e.Wrap("get links failed with an error: ", err)
 	}
 
-	return nil
+	userRow, err := c.Event.GetUser(ownerID(message.Chat))
+	if err != nil {
+		return e.Wrap("get user failed with an error: ", err)
+	}
+
+	timezone := tz.Default
+	if userRow != nil {
+		timezone = userRow.Timezone
+	}
+
+	lc := c.locale(message.Chat, message.From)
+	now := time.Now()
+	msg := lc.T(msgNoLinks)
+
+	if len(linkRows) > 0 {
+		msg = lc.T(msgLinksHeader)
+
+		if userRow != nil && userRow.SnoozeUntil.After(now) {
+			msg = lc.T(msgAllSnoozed, tz.Format(userRow.SnoozeUntil, timezone)) + msg
+		}
+
+		for _, l := range linkRows {
+			msg = msg + linkTitle(lc, l)
+
+			switch {
+			case l.Paused:
+				msg = msg + lc.T(msgPausedMark)
+			case l.SnoozeUntil.After(now):
+				msg = msg + lc.T(msgSnoozedMark, tz.Format(l.SnoozeUntil, timezone))
+			case l.Checked:
+				msg = msg + lc.T(msgLastCheck, tz.Format(l.LastCheck, timezone))
+			}
+
+			msg = msg + "\n"
+		}
+	}
+
+	return c.send(message, msg)
 }
 
-func (c *Commands) sendUnknownCommand(message *tgbotapi.Message) error {
-	m := tgbotapi.NewMessage(message.Chat.ID, msgUnknownCommand)
-	replyKeyboardHide := tgbotapi.ReplyKeyboardHide{HideKeyboard: true}
-	m.ReplyMarkup = replyKeyboardHide
-	_, err := c.BotAPI.Send(m)
+func (c *Commands) setPriceAlert(message *tgbotapi.Message, args []string, enabled bool) error {
+	link, below, ok := parsePriceArgs(args)
+	if !ok {
+		return c.sendText(message, msgPriceUsage)
+	}
+
+	err := c.Event.SetPriceAlert(ownerID(message.Chat), link, enabled, below)
+	if err != nil && !errors.Is(err, events.ErrLinkNotDB) && !errors.Is(err, events.ErrRefNotFound) {
+		return e.Wrap("set price alert failed with an error: ", err)
+	}
+
+	switch {
+	case err != nil:
+		return c.sendText(message, msgLinkNotTracked)
+	case enabled && below > 0:
+		return c.sendText(message, msgPriceBelow, below)
+	case enabled:
+		return c.sendText(message, msgPriceOn)
+	}
+
+	return c.sendText(message, msgPriceOff)
+}
+
+// setAlert включает или отключает один из типов оповещений для ссылки.
+func (c *Commands) setAlert(message *tgbotapi.Message, args []string, set func(userID int, link string, enabled bool) error, enabled bool, msg msgKey) error {
+	if len(args) != 1 || !isURL(args[0]) {
+		return c.sendText(message, msgAlertUsage)
+	}
+
+	err := set(ownerID(message.Chat), args[0], enabled)
+	if err != nil && !errors.Is(err, events.ErrLinkNotDB) && !errors.Is(err, events.ErrRefNotFound) {
+		return e.Wrap("set alert failed with an error: ", err)
+	}
+
 	if err != nil {
-		return e.Wrap("Sending the message failed with an error: ", err)
+		msg = msgLinkNotTracked
 	}
 
-	return nil
+	return c.sendText(message, msg)
 }
 
-func (c *Commands) addNewUser(message *tgbotapi.Message) error {
-	err := c.Event.AddNewUser(message.From.UserName, message.From.ID, message.Chat.ID)
+// setDelivery переключает доставку оповещений между мгновенной и сводкой.
+func (c *Commands) setDelivery(message *tgbotapi.Message, args []string) error {
+	delivery, hour, ok := parseDigestArgs(args)
+	if !ok {
+		return c.sendText(message, msgDigestUsage)
+	}
 
-	if err != nil && !errors.Is(err, events.ErrUserAlreadyAdded) {
+	if err := c.addNewUser(message); err != nil {
 		return e.Wrap("save new user failed with an error: ", err)
 	}
 
-	return nil
+	if err := c.Event.SetDelivery(ownerID(message.Chat), delivery, hour); err != nil {
+		return e.Wrap("set delivery failed with an error: ", err)
+	}
+
+	switch delivery {
+	case events.DeliveryDaily:
+		return c.sendText(message, msgDigestDaily, hour)
+	case events.DeliveryWeekly:
+		return c.sendText(message, msgDigestWeekly, hour)
+	}
+
+	return c.sendText(message, msgDigestOff)
 }
 
-func (c *Commands) saveLink(message *tgbotapi.Message) error {
-	err := c.Event.AddNewRefUserLink(message.From.ID, message.Text)
-	if err != nil && !errors.Is(err, events.ErrLinkAlreadyExists) {
-		return e.Wrap("save link failed with an error: ", err)
+// setQuietHours задает или отключает тихие часы пользователя.
+func (c *Commands) setQuietHours(message *tgbotapi.Message, args []string) error {
+	start, end, ok := parseQuietArgs(args)
+	if !ok {
+		return c.sendText(message, msgQuietUsage)
 	}
-	msg := "Ссылка сохранена для отслеживания."
 
-	if errors.Is(err, events.ErrLinkAlreadyExists) {
-		msg = "Такая ссылка уже добавлялась."
+	if err := c.addNewUser(message); err != nil {
+		return e.Wrap("save new user failed with an error: ", err)
 	}
 
-	m := tgbotapi.NewMessage(message.Chat.ID, msg)
-	replyKeyboardHide := tgbotapi.ReplyKeyboardHide{HideKeyboard: true}
-	m.ReplyMarkup = replyKeyboardHide
-	_, err = c.BotAPI.Send(m)
-	if err != nil {
-		return e.Wrap("Sending the message failed with an error: ", err)
+	if err := c.Event.SetQuietHours(ownerID(message.Chat), start, end); err != nil {
+		return e.Wrap("set quiet hours failed with an error: ", err)
 	}
 
-	return nil
+	if start != end {
+		return c.sendText(message, msgQuietOn, formatMinutes(start), formatMinutes(end))
+	}
+
+	return c.sendText(message, msgQuietOff)
 }
 
-func (c *Commands) removeLink(message *tgbotapi.Message) error {
-	text := strings.TrimSpace(message.Text)[2:]
+// setTimezone сохраняет часовой пояс пользователя.
+func (c *Commands) setTimezone(message *tgbotapi.Message, args []string) error {
+	if len(args) != 1 {
+		return c.sendText(message, msgTimezoneUsage)
+	}
+
+	if err := c.addNewUser(message); err != nil {
+		return e.Wrap("save new user failed with an error: ", err)
+	}
 
-	link := strings.TrimSpace(text)
+	err := c.Event.SetTimezone(ownerID(message.Chat), args[0])
+	if err != nil && !errors.Is(err, events.ErrBadTimezone) {
+		return e.Wrap("set timezone failed with an error: ", err)
+	}
 
-	err := c.Event.RemoveRefUserLink(message.From.ID, link)
 	if err != nil {
-		return e.Wrap("remove link failed with an error: ", err)
+		return c.sendText(message, msgTimezoneUnknown)
 	}
 
-	msg := "Ссылка больше не отслеживается."
+	return c.sendText(message, msgTimezoneSet, args[0])
+}
 
+// sendText отправляет сообщение по ключу на языке пользователя. Аргументы
+// экранируются для разметки HTML.
+func (c *Commands) sendText(message *tgbotapi.Message, key msgKey, args ...interface{}) error {
+	return c.send(message, c.locale(message.Chat, message.From).HTML(key, args...))
+}
+
+// send отправляет ответ в разметке HTML. Значения от пользователя и с
+// площадки в msg должны быть экранированы.
+func (c *Commands) send(message *tgbotapi.Message, msg string) error {
 	m := tgbotapi.NewMessage(message.Chat.ID, msg)
+	m.ParseMode = tgbotapi.ModeHTML
 	replyKeyboardHide := tgbotapi.ReplyKeyboardHide{HideKeyboard: true}
 	m.ReplyMarkup = replyKeyboardHide
-	_, err = c.BotAPI.Send(m)
+	_, err := c.BotAPI.Send(m)
 	if err != nil {
 		return e.Wrap("Sending the message failed with an error: ", err)
 	}
@@ -151,35 +568,171 @@ func (c *Commands) removeLink(message *tgbotapi.Message) error {
 	return nil
 }
 
-func (c *Commands) getAllLinks(message *tgbotapi.Message) error {
-	linkRows, err := c.Event.GetAllUserLinks(message.From.ID)
-	if err != nil {
-		return e.Wrap("get links failed with an error: ", err)
+// isImportCaption сообщает, подписан ли файл командой /import этому боту.
+func (c *Commands) isImportCaption(caption string) bool {
+	cmd, _ := splitCommand(strings.TrimSpace(caption))
+	cmd, mention := splitMention(cmd)
+
+	return cmd == ImportCmd && (mention == "" || strings.EqualFold(mention, c.BotAPI.Self.UserName))
+}
+
+// splitCommand отделяет команду от ее аргументов.
+func splitCommand(text string) (string, []string) {
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return "", nil
+	}
+
+	return fields[0], fields[1:]
+}
+
+// commandLabel возвращает метку сообщения для метрик. Неизвестные
+// команды учитываются вместе, чтобы число меток было ограничено.
+func commandLabel(message *tgbotapi.Message) string {
+	text := strings.TrimSpace(message.Text)
+	cmd, _ := splitCommand(text)
+	cmd, _ = splitMention(cmd)
+
+	switch {
+	case message.MigrateToChatID != 0:
+		return labelMigrate
+	case message.Document != nil:
+		return labelDocument
+	case isRemoveLink(text):
+		return labelRemove
+	case knownCmds[cmd]:
+		return cmd
+	case strings.HasPrefix(text, "/"):
+		return labelUnknown
+	case len(extractLinks(message)) > 0:
+		return labelLink
+	default:
+		return labelText
 	}
+}
 
-	msg := "У вас нет отслеживаемых ссылок"
+// splitMention отделяет от команды имя бота, которое Telegram добавляет
+// в группах: /all@authorBot.
+func splitMention(cmd string) (string, string) {
+	if !strings.HasPrefix(cmd, "/") {
+		return cmd, ""
+	}
 
-	if len(linkRows) > 0 {
-		msg = "Ваши отслеживаемые ссылки:\n"
+	i := strings.Index(cmd, "@")
+	if i < 0 {
+		return cmd, ""
+	}
 
-		for _, l := range linkRows {
-			msg = msg + l.Link + "\n"
+	return cmd[:i], cmd[i+1:]
+}
+
+// parsePriceArgs разбирает аргументы вида "<ссылка> [порог]".
+func parsePriceArgs(args []string) (string, int, bool) {
+	if len(args) == 0 || len(args) > 2 || !isURL(args[0]) {
+		return "", 0, false
+	}
+
+	if len(args) == 1 {
+		return args[0], 0, true
+	}
+
+	below, err := strconv.Atoi(args[1])
+	if err != nil || below <= 0 {
+		return "", 0, false
+	}
+
+	return args[0], below, true
+}
+
+// parseDigestArgs разбирает аргументы вида "daily|weekly|off [час]".
+func parseDigestArgs(args []string) (string, int, bool) {
+	if len(args) == 0 || len(args) > 2 {
+		return "", 0, false
+	}
+
+	var delivery string
+
+	switch args[0] {
+	case "daily":
+		delivery = events.DeliveryDaily
+	case "weekly":
+		delivery = events.DeliveryWeekly
+	case "off":
+		if len(args) != 1 {
+			return "", 0, false
 		}
+
+		return events.DeliveryInstant, defaultDigestHour, true
+	default:
+		return "", 0, false
 	}
 
-	m := tgbotapi.NewMessage(message.Chat.ID, msg)
-	replyKeyboardHide := tgbotapi.ReplyKeyboardHide{HideKeyboard: true}
-	m.ReplyMarkup = replyKeyboardHide
-	_, err = c.BotAPI.Send(m)
+	if len(args) == 1 {
+		return delivery, defaultDigestHour, true
+	}
+
+	hour, err := strconv.Atoi(args[1])
+	if err != nil || hour < 0 || hour > 23 {
+		return "", 0, false
+	}
+
+	return delivery, hour, true
+}
+
+// parseQuietArgs разбирает аргументы вида "23:00-08:00" или "off" и
+// возвращает границы тихих часов в минутах от полуночи.
+func parseQuietArgs(args []string) (int, int, bool) {
+	if len(args) != 1 {
+		return 0, 0, false
+	}
+
+	if args[0] == "off" {
+		return 0, 0, true
+	}
+
+	bounds := strings.Split(args[0], "-")
+	if len(bounds) != 2 {
+		return 0, 0, false
+	}
+
+	start, err := time.Parse("15:04", bounds[0])
 	if err != nil {
-		return e.Wrap("Sending the message failed with an error: ", err)
+		return 0, 0, false
 	}
 
-	return nil
+	end, err := time.Parse("15:04", bounds[1])
+	if err != nil {
+		return 0, 0, false
+	}
+
+	startMin := start.Hour()*60 + start.Minute()
+	endMin := end.Hour()*60 + end.Minute()
+
+	if startMin == endMin {
+		return 0, 0, false
+	}
+
+	return startMin, endMin, true
+}
+
+func formatMinutes(m int) string {
+	return fmt.Sprintf("%02d:%02d", m/60, m%60)
 }
 
+// linkTitle возвращает строку для списка ссылок. Циклы показываются
+// названием и числом томов, остальные ссылки как есть.
+func linkTitle(lc locale, l *sqlapi.LinkRow) string {
+	if l.Kind == sources.KindSeries && l.Title != "" {
+		return lc.HTML(msgSeriesItem, l.Title, l.Volumes)
+	}
+
+	return render.Escape(l.Link)
+}
+
+// isAddCmd сообщает, может ли сообщение содержать ссылки для
+// отслеживания: команды и удаление ссылок разбираются отдельно.
 func isAddCmd(text string) bool {
-	return isURL(text)
+	return !strings.HasPrefix(text, "/")
 }
 
 // TODO проверять ссылку, работает или нет
@@ -190,18 +743,11 @@ func isURL(text string) bool {
 }
 
 func isRemoveLink(text string) bool {
-	prefix := strings.TrimSpace(text)[0:2]
-	remove := false
-
-	if prefix == "rm" {
-		remove = true
-	}
-
-	if remove {
-		link := strings.TrimSpace(text[2:])
+	text = strings.TrimSpace(text)
 
-		remove = isURL(link)
+	if !strings.HasPrefix(text, "rm") {
+		return false
 	}
 
-	return remove
+	return isURL(strings.TrimSpace(text[2:]))
 }
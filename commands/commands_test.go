@@ -0,0 +1,223 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"path"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/EfimoffN/authorBot/events"
+	"github.com/EfimoffN/authorBot/sources"
+	"github.com/EfimoffN/authorBot/sqlapi"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+// fakeEvent — слой событий для тестов команд. Методы, которые тест не
+// переопределил, вызывают панику. Вызовы, меняющие данные, записываются в
+// calls.
+type fakeEvent struct {
+	events.IEvent
+	addLink func(userID int, link string) error
+	tracked map[string]bool
+	calls   []string
+}
+
+func (f *fakeEvent) AddNewRefUserLink(userID int, link string) error {
+	f.calls = append(f.calls, fmt.Sprintf("add %d %s", userID, link))
+
+	if f.addLink == nil {
+		return nil
+	}
+
+	return f.addLink(userID, link)
+}
+
+func (f *fakeEvent) GetUser(userID int) (*sqlapi.UserRow, error) {
+	return nil, nil
+}
+
+func (f *fakeEvent) GetSubscription(userID int, link string) (*sqlapi.RefRow, error) {
+	if !f.tracked[link] {
+		return nil, events.ErrRefNotFound
+	}
+
+	return &sqlapi.RefRow{Link: link}, nil
+}
+
+func (f *fakeEvent) SetBlocked(userID int, blocked bool) error {
+	f.calls = append(f.calls, fmt.Sprintf("blocked %d %v", userID, blocked))
+	return nil
+}
+
+func (f *fakeEvent) JoinChat(name string, userID int, chatID int64) error {
+	f.calls = append(f.calls, fmt.Sprintf("join %s %d %d", name, userID, chatID))
+	return nil
+}
+
+func (f *fakeEvent) LeaveChannel(chatID int64) error {
+	f.calls = append(f.calls, fmt.Sprintf("leave %d", chatID))
+	return nil
+}
+
+// fakeTelegram подменяет API Telegram и запоминает тексты отправленных
+// сообщений. На getChatMember отвечает статусом status.
+type fakeTelegram struct {
+	status string
+	texts  []string
+}
+
+func (f *fakeTelegram) RoundTrip(r *http.Request) (*http.Response, error) {
+	if err := r.ParseForm(); err != nil {
+		return nil, err
+	}
+
+	result := `{"message_id":1,"chat":{"id":1}}`
+
+	if path.Base(r.URL.Path) == "getChatMember" {
+		result = fmt.Sprintf(`{"user":{"id":1},"status":%q}`, f.status)
+	} else {
+		f.texts = append(f.texts, r.PostForm.Get("text"))
+	}
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{},
+		Body:       io.NopCloser(strings.NewReader(`{"ok":true,"result":` + result + `}`)),
+	}, nil
+}
+
+func newTestCommands(event events.IEvent, ctx context.Context) *Commands {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	return NewBotCommands(nil, event, nil, Settings{}, log, ctx)
+}
+
+// newTestBot возвращает клиент Telegram, который вместо сети обращается к tg.
+func newTestBot(tg *fakeTelegram) *tgbotapi.BotAPI {
+	return &tgbotapi.BotAPI{Token: "token", Client: &http.Client{Transport: tg}}
+}
+
+func Test_saveLink(t *testing.T) {
+	l := locale(langRU)
+	errDB := errors.New("connection reset")
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name    string
+		err     error
+		ctx     context.Context
+		want    string
+		wantErr bool
+	}{
+		{name: "saved", ctx: context.Background(), want: l.T(msgLinkSaved)},
+		{name: "exists", err: events.ErrLinkAlreadyExists, ctx: context.Background(), want: l.T(msgLinkExists)},
+		{name: "unsupported link", err: sources.ErrUnsupportedLink, ctx: context.Background(), want: l.T(msgUnsupportedLink)},
+		{name: "unexpected error", err: errDB, ctx: context.Background(), want: l.T(msgLinkFailed)},
+		{name: "canceled", err: errDB, ctx: canceled, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := &fakeEvent{addLink: func(int, string) error { return tt.err }}
+			c := newTestCommands(event, tt.ctx)
+
+			got, err := c.saveLink(l, 1, "https://author.today/work/1")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("saveLink() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got != tt.want {
+				t.Errorf("saveLink() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_doCommandGroup(t *testing.T) {
+	const link = "https://author.today/work/1"
+
+	l := locale(langRU)
+
+	tests := []struct {
+		name      string
+		status    string
+		message   tgbotapi.Message
+		wantCalls []string
+		wantReply string
+	}{
+		{
+			name:    "link in chat",
+			status:  "administrator",
+			message: tgbotapi.Message{Text: "Посмотрите " + link},
+		},
+		{
+			name:    "link removal in chat",
+			status:  "administrator",
+			message: tgbotapi.Message{Text: "- " + link},
+		},
+		{
+			name:    "file without caption",
+			status:  "administrator",
+			message: tgbotapi.Message{Document: &tgbotapi.Document{FileName: "subscriptions.json"}},
+		},
+		{
+			name:    "file for another bot",
+			status:  "administrator",
+			message: tgbotapi.Message{Document: &tgbotapi.Document{FileName: "subscriptions.json"}, Caption: "/import@other_bot"},
+		},
+		{
+			name:      "file with import caption",
+			status:    "administrator",
+			message:   tgbotapi.Message{Document: &tgbotapi.Document{FileName: "subscriptions.json", FileSize: maxImportSize + 1}, Caption: "/import"},
+			wantReply: l.T(msgImportTooLarge),
+		},
+		{
+			name:      "add command by admin",
+			status:    "administrator",
+			message:   tgbotapi.Message{Text: "/add " + link},
+			wantCalls: []string{"add -100 " + link},
+			wantReply: l.T(msgLinkSaved),
+		},
+		{
+			name:      "add command by member",
+			status:    "member",
+			message:   tgbotapi.Message{Text: "/add " + link},
+			wantReply: l.T(msgAdminsOnly),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := &fakeEvent{}
+			tg := &fakeTelegram{status: tt.status}
+
+			c := newTestCommands(event, context.Background())
+			c.BotAPI = newTestBot(tg)
+			c.BotAPI.Self.UserName = "author_bot"
+
+			message := tt.message
+			message.Chat = &tgbotapi.Chat{ID: -100, Type: "supergroup", Title: "Читатели"}
+			message.From = &tgbotapi.User{ID: 7}
+
+			if err := c.doCommand(&message); err != nil {
+				t.Fatalf("doCommand() error = %v", err)
+			}
+
+			if !reflect.DeepEqual(event.calls, tt.wantCalls) {
+				t.Errorf("doCommand() calls = %v, want %v", event.calls, tt.wantCalls)
+			}
+
+			if got := strings.Join(tg.texts, "|"); got != tt.wantReply {
+				t.Errorf("doCommand() replies = %q, want %q", got, tt.wantReply)
+			}
+		})
+	}
+}
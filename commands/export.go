@@ -0,0 +1,421 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/EfimoffN/authorBot/events"
+	"github.com/EfimoffN/authorBot/lib/e"
+	"github.com/EfimoffN/authorBot/lib/keywords"
+	"github.com/EfimoffN/authorBot/lib/logger"
+	"github.com/EfimoffN/authorBot/sources"
+	"github.com/EfimoffN/authorBot/sqlapi"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+// Форматы файла с подписками.
+const (
+	formatJSON = "json"
+	formatCSV  = "csv"
+)
+
+// maxImportSize — наибольший размер файла, который принимает импорт.
+const maxImportSize = 1 << 20
+
+const downloadTimeout = 30 * time.Second
+
+var (
+	errBadFile   = errors.New("unreadable subscriptions file")
+	errFileLarge = errors.New("subscriptions file is too large")
+)
+
+// csvHeader — колонки CSV-файла с подписками.
+var csvHeader = []string{"link", "kind", "title", "added", "chapters", "price", "price_below", "status", "annotation", "blog", "comments", "include", "exclude"}
+
+var downloadClient = &http.Client{Timeout: downloadTimeout}
+
+// exportEntry — подписка в файле экспорта.
+type exportEntry struct {
+	Link    string        `json:"link"`
+	Kind    string        `json:"kind"`
+	Title   string        `json:"title"`
+	Added   time.Time     `json:"added"`
+	Filters exportFilters `json:"filters"`
+}
+
+type exportFilters struct {
+	Chapters   bool   `json:"chapters"`
+	Price      bool   `json:"price"`
+	PriceBelow int    `json:"price_below"`
+	Status     bool   `json:"status"`
+	Annotation bool   `json:"annotation"`
+	Blog       bool   `json:"blog"`
+	Comments   bool   `json:"comments"`
+	Include    string `json:"include"`
+	Exclude    string `json:"exclude"`
+}
+
+// exportLinks отправляет подписки пользователя файлом JSON или CSV.
+func (c *Commands) exportLinks(message *tgbotapi.Message, args []string) error {
+	format := formatJSON
+
+	switch {
+	case len(args) > 1:
+		return c.sendText(message, msgExportUsage)
+	case len(args) == 1 && (args[0] == formatJSON || args[0] == formatCSV):
+		format = args[0]
+	case len(args) == 1:
+		return c.sendText(message, msgExportUsage)
+	}
+
+	refRows, err := c.Event.GetSubscriptions(ownerID(message.Chat))
+	if err != nil {
+		return e.Wrap("get subscriptions failed with an error: ", err)
+	}
+
+	if len(refRows) == 0 {
+		return c.sendText(message, msgExportEmpty)
+	}
+
+	entries := make([]exportEntry, 0, len(refRows))
+	for _, ref := range refRows {
+		entries = append(entries, newExportEntry(ref))
+	}
+
+	data, err := encodeEntries(format, entries)
+	if err != nil {
+		return e.Wrap("encode subscriptions failed with an error: ", err)
+	}
+
+	doc := tgbotapi.NewDocumentUpload(message.Chat.ID, tgbotapi.FileBytes{Name: "subscriptions." + format, Bytes: data})
+	doc.Caption = c.locale(message.Chat, message.From).T(msgExportDone, len(entries))
+
+	if _, err := c.BotAPI.Send(doc); err != nil {
+		return e.Wrap("Sending the document failed with an error: ", err)
+	}
+
+	return nil
+}
+
+// importLinks подписывает пользователя на ссылки из присланного файла и
+// сообщает, сколько ссылок добавлено, пропущено, отклонено и не добавлено
+// из-за ошибки. Ошибка одной ссылки не прерывает загрузку остальных.
+func (c *Commands) importLinks(message *tgbotapi.Message) error {
+	if message.Document.FileSize > maxImportSize {
+		return c.sendText(message, msgImportTooLarge)
+	}
+
+	data, err := c.downloadFile(message.Document.FileID)
+	if errors.Is(err, errFileLarge) {
+		return c.sendText(message, msgImportTooLarge)
+	}
+
+	if err != nil {
+		return e.Wrap("download file failed with an error: ", err)
+	}
+
+	entries, invalid, err := decodeEntries(message.Document.FileName, data)
+	if err != nil {
+		return c.sendText(message, msgImportBadFile)
+	}
+
+	if err := c.addNewUser(message); err != nil {
+		return e.Wrap("save new user failed with an error: ", err)
+	}
+
+	added, skipped, failed := 0, 0, 0
+
+	for _, en := range entries {
+		if !isURL(en.Link) {
+			invalid++
+
+			continue
+		}
+
+		err := c.Event.ImportSubscription(ownerID(message.Chat), en.refRow())
+
+		switch {
+		case err == nil:
+			added++
+		case errors.Is(err, events.ErrLinkAlreadyExists):
+			skipped++
+		case errors.Is(err, sources.ErrUnsupported), errors.Is(err, sources.ErrUnsupportedLink):
+			invalid++
+		case c.CTX.Err() != nil:
+			return e.Wrap("import subscription failed with an error: ", err)
+		default:
+			c.Log.Error("import subscription", logger.KeyLink, en.Link, logger.Err(err))
+			failed++
+		}
+	}
+
+	return c.sendText(message, msgImportDone, added, skipped, invalid, failed)
+}
+
+// downloadFile скачивает файл, присланный боту, не больше maxImportSize байт.
+func (c *Commands) downloadFile(fileID string) ([]byte, error) {
+	fileURL, err := c.BotAPI.GetFileDirectURL(fileID)
+	if err != nil {
+		return nil, e.Wrap("get file URL failed with an error: ", err)
+	}
+
+	resp, err := downloadClient.Get(fileURL)
+	if err != nil {
+		return nil, e.Wrap("get file failed with an error: ", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("get file: unexpected status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxImportSize+1))
+	if err != nil {
+		return nil, e.Wrap("read file failed with an error: ", err)
+	}
+
+	if len(data) > maxImportSize {
+		return nil, errFileLarge
+	}
+
+	return data, nil
+}
+
+func newExportEntry(ref *sqlapi.RefRow) exportEntry {
+	return exportEntry{
+		Link:  ref.Link,
+		Kind:  ref.Kind,
+		Title: ref.Title,
+		Added: ref.Created,
+		Filters: exportFilters{
+			Chapters:   ref.ChapterAlert,
+			Price:      ref.PriceAlert,
+			PriceBelow: ref.PriceBelow,
+			Status:     ref.StatusAlert,
+			Annotation: ref.AnnotationAlert,
+			Blog:       ref.BlogAlert,
+			Comments:   ref.CommentAlert,
+			Include:    ref.Include,
+			Exclude:    ref.Exclude,
+		},
+	}
+}
+
+// defaultEntry возвращает подписку с настройками новой подписки. Ими
+// заполняются поля, которых нет в импортируемом файле.
+func defaultEntry() exportEntry {
+	return exportEntry{
+		Filters: exportFilters{
+			Chapters:   true,
+			Status:     true,
+			Annotation: true,
+			Blog:       true,
+			Comments:   true,
+		},
+	}
+}
+
+// refRow возвращает настройки подписки для сохранения.
+func (en exportEntry) refRow() sqlapi.RefRow {
+	return sqlapi.RefRow{
+		Link:            strings.TrimSpace(en.Link),
+		ChapterAlert:    en.Filters.Chapters,
+		PriceAlert:      en.Filters.Price,
+		PriceBelow:      en.Filters.PriceBelow,
+		StatusAlert:     en.Filters.Status,
+		AnnotationAlert: en.Filters.Annotation,
+		BlogAlert:       en.Filters.Blog,
+		CommentAlert:    en.Filters.Comments,
+		Include:         keywords.Normalize(en.Filters.Include),
+		Exclude:         keywords.Normalize(en.Filters.Exclude),
+	}
+}
+
+func encodeEntries(format string, entries []exportEntry) ([]byte, error) {
+	if format == formatJSON {
+		data, err := json.MarshalIndent(entries, "", "  ")
+		if err != nil {
+			return nil, e.Wrap("marshal subscriptions failed with an error: ", err)
+		}
+
+		return data, nil
+	}
+
+	buf := &bytes.Buffer{}
+	w := csv.NewWriter(buf)
+
+	if err := w.Write(csvHeader); err != nil {
+		return nil, e.Wrap("write csv header failed with an error: ", err)
+	}
+
+	for _, en := range entries {
+		record := []string{
+			en.Link,
+			en.Kind,
+			en.Title,
+			en.Added.UTC().Format(time.RFC3339),
+			strconv.FormatBool(en.Filters.Chapters),
+			strconv.FormatBool(en.Filters.Price),
+			strconv.Itoa(en.Filters.PriceBelow),
+			strconv.FormatBool(en.Filters.Status),
+			strconv.FormatBool(en.Filters.Annotation),
+			strconv.FormatBool(en.Filters.Blog),
+			strconv.FormatBool(en.Filters.Comments),
+			en.Filters.Include,
+			en.Filters.Exclude,
+		}
+
+		if err := w.Write(record); err != nil {
+			return nil, e.Wrap("write csv record failed with an error: ", err)
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, e.Wrap("flush csv failed with an error: ", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// decodeEntries разбирает файл с подписками. Формат определяется по
+// расширению имени файла, а без него — по содержимому. Вместе с подписками
+// возвращается число записей, которые не удалось разобрать; errBadFile
+// означает, что файл не читается целиком.
+func decodeEntries(name string, data []byte) ([]exportEntry, int, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	switch strings.ToLower(path.Ext(name)) {
+	case "." + formatJSON:
+		return decodeJSON(data)
+	case "." + formatCSV:
+		return decodeCSV(data)
+	}
+
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		return decodeJSON(data)
+	}
+
+	return decodeCSV(data)
+}
+
+func decodeJSON(data []byte) ([]exportEntry, int, error) {
+	raws := []json.RawMessage{}
+
+	if err := json.Unmarshal(data, &raws); err != nil {
+		return nil, 0, e.Wrap("unmarshal subscriptions failed with an error: ", errBadFile)
+	}
+
+	entries := []exportEntry{}
+	invalid := 0
+
+	for _, raw := range raws {
+		en := defaultEntry()
+
+		if err := json.Unmarshal(raw, &en); err != nil || en.Filters.PriceBelow < 0 {
+			invalid++
+
+			continue
+		}
+
+		entries = append(entries, en)
+	}
+
+	return entries, invalid, nil
+}
+
+func decodeCSV(data []byte) ([]exportEntry, int, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+
+	records, err := r.ReadAll()
+	if err != nil || len(records) == 0 {
+		return nil, 0, e.Wrap("read csv failed with an error: ", errBadFile)
+	}
+
+	columns := map[string]int{}
+	for i, name := range records[0] {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	if _, ok := columns["link"]; !ok {
+		return nil, 0, e.Wrap("csv without link column: ", errBadFile)
+	}
+
+	entries := []exportEntry{}
+	invalid := 0
+
+	for _, record := range records[1:] {
+		en, ok := csvEntry(columns, record)
+		if !ok {
+			invalid++
+
+			continue
+		}
+
+		entries = append(entries, en)
+	}
+
+	return entries, invalid, nil
+}
+
+// csvEntry собирает подписку из строки CSV. Пустые и отсутствующие колонки
+// получают значения по умолчанию.
+func csvEntry(columns map[string]int, record []string) (exportEntry, bool) {
+	en := defaultEntry()
+
+	get := func(name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+
+		return strings.TrimSpace(record[i])
+	}
+
+	ok := true
+
+	setBool := func(name string, dst *bool) {
+		if v := get(name); v != "" {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				ok = false
+			}
+
+			*dst = b
+		}
+	}
+
+	en.Link = get("link")
+	en.Kind = get("kind")
+	en.Title = get("title")
+	en.Filters.Include = get("include")
+	en.Filters.Exclude = get("exclude")
+
+	setBool("chapters", &en.Filters.Chapters)
+	setBool("price", &en.Filters.Price)
+	setBool("status", &en.Filters.Status)
+	setBool("annotation", &en.Filters.Annotation)
+	setBool("blog", &en.Filters.Blog)
+	setBool("comments", &en.Filters.Comments)
+
+	if v := get("price_below"); v != "" {
+		below, err := strconv.Atoi(v)
+		if err != nil || below < 0 {
+			ok = false
+		}
+
+		en.Filters.PriceBelow = below
+	}
+
+	return en, ok
+}
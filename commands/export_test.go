@@ -0,0 +1,162 @@
+package commands
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_encodeDecodeEntries(t *testing.T) {
+	entries := []exportEntry{
+		{
+			Link:  "https://author.today/work/1",
+			Kind:  "book",
+			Title: "Книга, первая",
+			Added: time.Date(2023, 5, 10, 12, 0, 0, 0, time.UTC),
+			Filters: exportFilters{
+				Chapters:   true,
+				Price:      true,
+				PriceBelow: 100,
+				Blog:       true,
+				Include:    "том, продолжение",
+			},
+		},
+		{
+			Link:  "https://author.today/u/login",
+			Kind:  "author",
+			Added: time.Date(2023, 5, 11, 8, 30, 0, 0, time.UTC),
+			Filters: exportFilters{
+				Status:   true,
+				Comments: true,
+				Exclude:  "розыгрыш",
+			},
+		},
+	}
+
+	for _, format := range []string{formatJSON, formatCSV} {
+		t.Run(format, func(t *testing.T) {
+			data, err := encodeEntries(format, entries)
+			if err != nil {
+				t.Fatalf("encodeEntries() error = %v", err)
+			}
+
+			got, invalid, err := decodeEntries("subscriptions."+format, data)
+			if err != nil {
+				t.Fatalf("decodeEntries() error = %v", err)
+			}
+
+			if invalid != 0 {
+				t.Errorf("decodeEntries() invalid = %d, want 0", invalid)
+			}
+
+			if len(got) != len(entries) {
+				t.Fatalf("decodeEntries() got %d entries, want %d", len(got), len(entries))
+			}
+
+			for i := range entries {
+				if got[i].Link != entries[i].Link || got[i].Kind != entries[i].Kind || got[i].Title != entries[i].Title {
+					t.Errorf("decodeEntries() entry %d = %+v, want %+v", i, got[i], entries[i])
+				}
+
+				if !reflect.DeepEqual(got[i].Filters, entries[i].Filters) {
+					t.Errorf("decodeEntries() filters %d = %+v, want %+v", i, got[i].Filters, entries[i].Filters)
+				}
+			}
+		})
+	}
+}
+
+func Test_decodeEntries(t *testing.T) {
+	defaults := defaultEntry().Filters
+
+	tests := []struct {
+		name        string
+		file        string
+		data        string
+		wantLinks   []string
+		wantInvalid int
+		wantDefault bool
+		wantErr     bool
+	}{
+		{
+			name:        "json without filters gets defaults",
+			file:        "subs.json",
+			data:        `[{"link": "https://author.today/work/1"}]`,
+			wantLinks:   []string{"https://author.today/work/1"},
+			wantDefault: true,
+		},
+		{
+			name:        "json with broken entry",
+			file:        "subs.json",
+			data:        `[{"link": "https://author.today/work/1"}, {"link": 5}, {"link": "x", "filters": {"price_below": -1}}]`,
+			wantLinks:   []string{"https://author.today/work/1"},
+			wantInvalid: 2,
+		},
+		{
+			name:      "csv with link column only",
+			file:      "subs.csv",
+			data:      "\xef\xbb\xbfLink\nhttps://author.today/work/1\nhttps://author.today/work/2\n",
+			wantLinks: []string{"https://author.today/work/1", "https://author.today/work/2"},
+		},
+		{
+			name:        "csv with bad values",
+			file:        "subs.csv",
+			data:        "link,chapters,price_below\nhttps://author.today/work/1,yes,\nhttps://author.today/work/2,,abc\nhttps://author.today/work/3,false,10\n",
+			wantLinks:   []string{"https://author.today/work/3"},
+			wantInvalid: 2,
+		},
+		{
+			name:      "format by content",
+			file:      "subs",
+			data:      ` [{"link": "https://author.today/work/1"}]`,
+			wantLinks: []string{"https://author.today/work/1"},
+		},
+		{
+			name:    "csv without link column",
+			file:    "subs.csv",
+			data:    "kind,title\nbook,Книга\n",
+			wantErr: true,
+		},
+		{
+			name:    "not json",
+			file:    "subs.json",
+			data:    `{"link": "https://author.today/work/1"}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, invalid, err := decodeEntries(tt.file, []byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("decodeEntries() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr {
+				if !errors.Is(err, errBadFile) {
+					t.Errorf("decodeEntries() error = %v, want errBadFile", err)
+				}
+
+				return
+			}
+
+			if invalid != tt.wantInvalid {
+				t.Errorf("decodeEntries() invalid = %d, want %d", invalid, tt.wantInvalid)
+			}
+
+			links := []string{}
+			for _, en := range got {
+				links = append(links, en.Link)
+			}
+
+			if !reflect.DeepEqual(links, tt.wantLinks) {
+				t.Errorf("decodeEntries() links = %v, want %v", links, tt.wantLinks)
+			}
+
+			if tt.wantDefault && got[0].Filters != defaults {
+				t.Errorf("decodeEntries() filters = %+v, want %+v", got[0].Filters, defaults)
+			}
+		})
+	}
+}
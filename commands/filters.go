@@ -0,0 +1,268 @@
+package commands
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/EfimoffN/authorBot/events"
+	"github.com/EfimoffN/authorBot/lib/e"
+	"github.com/EfimoffN/authorBot/lib/keywords"
+	"github.com/EfimoffN/authorBot/metrics"
+	"github.com/EfimoffN/authorBot/sqlapi"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+// filterPrefix отмечает callback-данные кнопок меню настроек подписки:
+// "flt:<refid>:<ключ>".
+const filterPrefix = "flt"
+
+// Ключи кнопок меню настроек подписки.
+const (
+	filterChapters   = "chapters"
+	filterPrice      = "price"
+	filterStatus     = "status"
+	filterBlog       = "blog"
+	filterComments   = "comments"
+	filterAnnotation = "annotation"
+	filterNoInclude  = "noinclude"
+	filterNoExclude  = "noexclude"
+)
+
+// DoCallback обрабатывает нажатия на инлайн-кнопки по префиксу данных.
+func (c *Commands) DoCallback(query *tgbotapi.CallbackQuery) error {
+	started := time.Now()
+	err := c.doCallback(query)
+	metrics.ObserveUpdate(labelCallback, started, err)
+
+	return err
+}
+
+func (c *Commands) doCallback(query *tgbotapi.CallbackQuery) error {
+	if query.Message == nil {
+		return c.answerCallback(query, "")
+	}
+
+	banned, err := c.isBanned(query.Message.Chat, query.From)
+	if err != nil {
+		return e.Wrap("check banned failed with an error: ", err)
+	}
+
+	if banned {
+		return c.answerCallback(query, "")
+	}
+
+	if !query.Message.Chat.IsPrivate() {
+		admin, err := c.isChatAdmin(query.Message.Chat.ID, query.From.ID)
+		if err != nil {
+			return e.Wrap("check chat admin failed with an error: ", err)
+		}
+
+		if !admin {
+			return c.answerCallback(query, c.locale(query.Message.Chat, query.From).T(msgAdminsOnly))
+		}
+	}
+
+	parts := strings.Split(query.Data, ":")
+
+	switch {
+	case len(parts) == 3 && parts[0] == filterPrefix:
+		return c.doFilterCallback(query, parts[1], parts[2])
+	case len(parts) == 2 && parts[0] == stopPrefix:
+		return c.doStopCallback(query, parts[1])
+	default:
+		return c.answerCallback(query, "")
+	}
+}
+
+// doFilterCallback переключает настройку подписки и обновляет меню.
+func (c *Commands) doFilterCallback(query *tgbotapi.CallbackQuery, refID, key string) error {
+	l := c.locale(query.Message.Chat, query.From)
+
+	ref, err := c.Event.GetSubscriptionByID(ownerID(query.Message.Chat), refID)
+	if err != nil && !errors.Is(err, events.ErrRefNotFound) {
+		return e.Wrap("get subscription failed with an error: ", err)
+	}
+
+	if err != nil {
+		return c.answerCallback(query, l.T(msgLinkNotTracked))
+	}
+
+	if !toggleFilter(ref, key) {
+		return c.answerCallback(query, "")
+	}
+
+	if err := c.Event.UpdateSubscription(*ref); err != nil {
+		return e.Wrap("update subscription failed with an error: ", err)
+	}
+
+	if err := c.answerCallback(query, l.T(msgFilterSaved)); err != nil {
+		return err
+	}
+
+	text, markup := filterMenu(l, ref)
+
+	return c.editText(query.Message, text, &markup)
+}
+
+// sendFilterMenu показывает меню настроек подписки на ссылку.
+func (c *Commands) sendFilterMenu(message *tgbotapi.Message, args []string) error {
+	if len(args) != 1 || !isURL(args[0]) {
+		return c.sendText(message, msgFilterUsage)
+	}
+
+	ref, err := c.Event.GetSubscription(ownerID(message.Chat), args[0])
+	if err != nil && !errors.Is(err, events.ErrLinkNotDB) && !errors.Is(err, events.ErrRefNotFound) {
+		return e.Wrap("get subscription failed with an error: ", err)
+	}
+
+	if err != nil {
+		return c.sendText(message, msgLinkNotTracked)
+	}
+
+	text, markup := filterMenu(c.locale(message.Chat, message.From), ref)
+
+	m := tgbotapi.NewMessage(message.Chat.ID, text)
+	m.ParseMode = tgbotapi.ModeHTML
+	m.ReplyMarkup = markup
+
+	if _, err := c.BotAPI.Send(m); err != nil {
+		return e.Wrap("Sending the message failed with an error: ", err)
+	}
+
+	return nil
+}
+
+// setKeywords задает слова для включения или исключения записей блога
+// и комментариев. Без слов фильтр сбрасывается.
+func (c *Commands) setKeywords(message *tgbotapi.Message, args []string, include bool) error {
+	if len(args) == 0 || !isURL(args[0]) {
+		return c.sendText(message, msgKeywordsUsage)
+	}
+
+	ref, err := c.Event.GetSubscription(ownerID(message.Chat), args[0])
+	if err != nil && !errors.Is(err, events.ErrLinkNotDB) && !errors.Is(err, events.ErrRefNotFound) {
+		return e.Wrap("get subscription failed with an error: ", err)
+	}
+
+	if err != nil {
+		return c.sendText(message, msgLinkNotTracked)
+	}
+
+	words := keywords.Normalize(strings.Join(args[1:], " "))
+
+	if include {
+		ref.Include = words
+	} else {
+		ref.Exclude = words
+	}
+
+	if err := c.Event.UpdateSubscription(*ref); err != nil {
+		return e.Wrap("update subscription failed with an error: ", err)
+	}
+
+	l := c.locale(message.Chat, message.From)
+
+	return c.sendText(message, msgKeywordsSet, keywordsText(l, ref.Include), keywordsText(l, ref.Exclude))
+}
+
+func (c *Commands) answerCallback(query *tgbotapi.CallbackQuery, text string) error {
+	if _, err := c.BotAPI.AnswerCallbackQuery(tgbotapi.NewCallback(query.ID, text)); err != nil {
+		return e.Wrap("Answering the callback failed with an error: ", err)
+	}
+
+	return nil
+}
+
+// editText заменяет текст и кнопки сообщения. Текст — в разметке HTML. Без
+// кнопок они убираются.
+func (c *Commands) editText(message *tgbotapi.Message, text string, markup *tgbotapi.InlineKeyboardMarkup) error {
+	edit := tgbotapi.NewEditMessageText(message.Chat.ID, message.MessageID, text)
+	edit.ParseMode = tgbotapi.ModeHTML
+	edit.ReplyMarkup = markup
+
+	if _, err := c.BotAPI.Send(edit); err != nil {
+		return e.Wrap("Editing the message failed with an error: ", err)
+	}
+
+	return nil
+}
+
+// toggleFilter переключает настройку подписки по ключу кнопки.
+func toggleFilter(ref *sqlapi.RefRow, key string) bool {
+	switch key {
+	case filterChapters:
+		ref.ChapterAlert = !ref.ChapterAlert
+	case filterPrice:
+		ref.PriceAlert = !ref.PriceAlert
+	case filterStatus:
+		ref.StatusAlert = !ref.StatusAlert
+	case filterBlog:
+		ref.BlogAlert = !ref.BlogAlert
+	case filterComments:
+		ref.CommentAlert = !ref.CommentAlert
+	case filterAnnotation:
+		ref.AnnotationAlert = !ref.AnnotationAlert
+	case filterNoInclude:
+		ref.Include = ""
+	case filterNoExclude:
+		ref.Exclude = ""
+	default:
+		return false
+	}
+
+	return true
+}
+
+// filterMenu возвращает текст и кнопки меню настроек подписки.
+func filterMenu(l locale, ref *sqlapi.RefRow) (string, tgbotapi.InlineKeyboardMarkup) {
+	text := l.HTML(msgFilterMenu, ref.Link, keywordsText(l, ref.Include), keywordsText(l, ref.Exclude))
+
+	button := func(label msgKey, enabled bool, key string) tgbotapi.InlineKeyboardButton {
+		mark := msgFilterOff
+		if enabled {
+			mark = msgFilterOn
+		}
+
+		return tgbotapi.NewInlineKeyboardButtonData(mark+" "+l.T(label), filterPrefix+":"+ref.RefID+":"+key)
+	}
+
+	rows := [][]tgbotapi.InlineKeyboardButton{
+		tgbotapi.NewInlineKeyboardRow(
+			button(msgFilterChapters, ref.ChapterAlert, filterChapters),
+			button(msgFilterPrice, ref.PriceAlert, filterPrice),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			button(msgFilterStatus, ref.StatusAlert, filterStatus),
+			button(msgFilterAnnotation, ref.AnnotationAlert, filterAnnotation),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			button(msgFilterBlog, ref.BlogAlert, filterBlog),
+			button(msgFilterComments, ref.CommentAlert, filterComments),
+		),
+	}
+
+	clear := []tgbotapi.InlineKeyboardButton{}
+
+	if ref.Include != "" {
+		clear = append(clear, tgbotapi.NewInlineKeyboardButtonData(l.T(msgFilterNoInclude), filterPrefix+":"+ref.RefID+":"+filterNoInclude))
+	}
+
+	if ref.Exclude != "" {
+		clear = append(clear, tgbotapi.NewInlineKeyboardButtonData(l.T(msgFilterNoExclude), filterPrefix+":"+ref.RefID+":"+filterNoExclude))
+	}
+
+	if len(clear) > 0 {
+		rows = append(rows, clear)
+	}
+
+	return text, tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+func keywordsText(l locale, words string) string {
+	if words == "" {
+		return l.T(msgKeywordsNone)
+	}
+
+	return words
+}
@@ -0,0 +1,70 @@
+package commands
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf16"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+// Типы сущностей Telegram, в которых приходят ссылки.
+const (
+	entityURL      = "url"
+	entityTextLink = "text_link"
+)
+
+var linkRegexp = regexp.MustCompile(`https?://[^\s<>"]+`)
+
+// linkTrailing — знаки препинания, которые часто стоят сразу после ссылки
+// в тексте, но не входят в нее.
+const linkTrailing = `.,;:!?)]}»"'`
+
+// extractLinks возвращает ссылки из сообщения в порядке появления без
+// повторов: сначала размеченные Telegram (включая ссылки, спрятанные под
+// текстом), затем найденные в тексте.
+func extractLinks(message *tgbotapi.Message) []string {
+	links := []string{}
+	seen := map[string]bool{}
+
+	add := func(link string) {
+		link = strings.TrimRight(strings.TrimSpace(link), linkTrailing)
+		if link == "" {
+			return
+		}
+
+		if !strings.Contains(link, "://") {
+			link = "https://" + link
+		}
+
+		if !isURL(link) || seen[link] {
+			return
+		}
+
+		seen[link] = true
+		links = append(links, link)
+	}
+
+	if message.Entities != nil {
+		text := utf16.Encode([]rune(message.Text))
+
+		for _, entity := range *message.Entities {
+			switch entity.Type {
+			case entityURL:
+				if entity.Offset < 0 || entity.Length < 0 || entity.Offset+entity.Length > len(text) {
+					continue
+				}
+
+				add(string(utf16.Decode(text[entity.Offset : entity.Offset+entity.Length])))
+			case entityTextLink:
+				add(entity.URL)
+			}
+		}
+	}
+
+	for _, link := range linkRegexp.FindAllString(message.Text, -1) {
+		add(link)
+	}
+
+	return links
+}
@@ -0,0 +1,157 @@
+package commands
+
+import (
+	"reflect"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+func Test_extractLinks(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		entities *[]tgbotapi.MessageEntity
+		want     []string
+	}{
+		{
+			name: "single link",
+			text: "https://author.today/work/1",
+			want: []string{"https://author.today/work/1"},
+		},
+		{
+			name: "list with punctuation and repeats",
+			text: "1. https://author.today/work/1,\n2. https://author.today/work/2.\n3. (https://author.today/work/1)",
+			want: []string{"https://author.today/work/1", "https://author.today/work/2"},
+		},
+		{
+			name: "entities with utf-16 offsets",
+			text: "📚 Книга: author.today/work/3",
+			entities: &[]tgbotapi.MessageEntity{
+				{Type: entityURL, Offset: 10, Length: 19},
+			},
+			want: []string{"https://author.today/work/3"},
+		},
+		{
+			name: "hidden text link",
+			text: "вот эта книга и https://author.today/work/2",
+			entities: &[]tgbotapi.MessageEntity{
+				{Type: entityTextLink, Offset: 4, Length: 9, URL: "https://author.today/work/1"},
+				{Type: "bold", Offset: 0, Length: 3},
+			},
+			want: []string{"https://author.today/work/1", "https://author.today/work/2"},
+		},
+		{
+			name: "entity out of range",
+			text: "текст",
+			entities: &[]tgbotapi.MessageEntity{
+				{Type: entityURL, Offset: 3, Length: 10},
+			},
+			want: []string{},
+		},
+		{
+			name: "no links",
+			text: "просто текст",
+			want: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message := &tgbotapi.Message{Text: tt.text, Entities: tt.entities}
+
+			if got := extractLinks(message); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("extractLinks() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_isRemoveLink(t *testing.T) {
+	tests := []struct {
+		text string
+		want bool
+	}{
+		{text: "rm https://author.today/work/1", want: true},
+		{text: "rm текст", want: false},
+		{text: "https://author.today/work/1", want: false},
+		{text: "r", want: false},
+		{text: "", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got := isRemoveLink(tt.text); got != tt.want {
+				t.Errorf("isRemoveLink(%q) = %v, want %v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_splitMention(t *testing.T) {
+	tests := []struct {
+		cmd         string
+		wantCmd     string
+		wantMention string
+	}{
+		{cmd: "/all", wantCmd: "/all"},
+		{cmd: "/all@authorBot", wantCmd: "/all", wantMention: "authorBot"},
+		{cmd: "https://author.today/u/name@mail", wantCmd: "https://author.today/u/name@mail"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.cmd, func(t *testing.T) {
+			cmd, mention := splitMention(tt.cmd)
+			if cmd != tt.wantCmd || mention != tt.wantMention {
+				t.Errorf("splitMention(%q) = %q, %q, want %q, %q", tt.cmd, cmd, mention, tt.wantCmd, tt.wantMention)
+			}
+		})
+	}
+}
+
+func Test_commandLabel(t *testing.T) {
+	tests := []struct {
+		name    string
+		message *tgbotapi.Message
+		want    string
+	}{
+		{
+			name:    "known command with mention",
+			message: &tgbotapi.Message{Text: "/all@authorBot"},
+			want:    AllLinkCmd,
+		},
+		{
+			name:    "unknown command",
+			message: &tgbotapi.Message{Text: "/whatever 1"},
+			want:    labelUnknown,
+		},
+		{
+			name:    "link",
+			message: &tgbotapi.Message{Text: "https://author.today/work/1"},
+			want:    labelLink,
+		},
+		{
+			name:    "remove",
+			message: &tgbotapi.Message{Text: "rm https://author.today/work/1"},
+			want:    labelRemove,
+		},
+		{
+			name:    "document",
+			message: &tgbotapi.Message{Document: &tgbotapi.Document{FileName: "subs.json"}},
+			want:    labelDocument,
+		},
+		{
+			name:    "plain text",
+			message: &tgbotapi.Message{Text: "привет"},
+			want:    labelText,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := commandLabel(tt.message); got != tt.want {
+				t.Errorf("commandLabel() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/EfimoffN/authorBot/lib/e"
+	"github.com/EfimoffN/authorBot/lib/logger"
+	"github.com/EfimoffN/authorBot/render"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+// Языки сообщений бота.
+const (
+	langRU = "ru"
+	langEN = "en"
+)
+
+// langAuto в /lang возвращает выбор языка по настройкам Telegram.
+const langAuto = "auto"
+
+// defaultLang — язык, если Telegram не сообщил язык пользователя.
+const defaultLang = langRU
+
+// msgKey — ключ сообщения в каталоге переводов.
+type msgKey string
+
+// catalog — тексты сообщений бота на каждом языке.
+var catalog = map[string]map[msgKey]string{
+	langRU: messagesRU,
+	langEN: messagesEN,
+}
+
+// locale — язык, на котором бот отвечает пользователю.
+type locale string
+
+// T возвращает текст сообщения на языке l, подставляя в него args.
+// Сообщения, которых нет в каталоге языка, берутся на языке по умолчанию.
+func (l locale) T(key msgKey, args ...interface{}) string {
+	text, ok := catalog[string(l)][key]
+	if !ok {
+		text = catalog[defaultLang][key]
+	}
+
+	if len(args) == 0 {
+		return text
+	}
+
+	return fmt.Sprintf(text, args...)
+}
+
+// HTML возвращает текст сообщения для разметки HTML: как T, но строковые
+// аргументы, например ссылки и названия книг, экранируются.
+func (l locale) HTML(key msgKey, args ...interface{}) string {
+	escaped := make([]interface{}, len(args))
+
+	for i, arg := range args {
+		if s, ok := arg.(string); ok {
+			arg = render.Escape(s)
+		}
+
+		escaped[i] = arg
+	}
+
+	return l.T(key, escaped...)
+}
+
+// locale возвращает язык владельца подписок чата: выбранный командой
+// /lang, а если он не выбран — по настройкам Telegram отправителя.
+func (c *Commands) locale(chat *tgbotapi.Chat, from *tgbotapi.User) locale {
+	userRow, err := c.Event.GetUser(ownerID(chat))
+	if err != nil {
+		c.Log.Warn("get user language", logger.Err(err))
+	}
+
+	if err == nil && userRow != nil && userRow.Lang != "" {
+		return locale(userRow.Lang)
+	}
+
+	code := ""
+	if from != nil {
+		code = from.LanguageCode
+	}
+
+	return locale(languageFromCode(code))
+}
+
+// setLanguage сохраняет язык сообщений или возвращает выбор языка по
+// настройкам Telegram.
+func (c *Commands) setLanguage(message *tgbotapi.Message, args []string) error {
+	if len(args) != 1 {
+		return c.sendText(message, msgLangUsage)
+	}
+
+	lang := strings.ToLower(args[0])
+	if _, ok := catalog[lang]; !ok && lang != langAuto {
+		return c.sendText(message, msgLangUsage)
+	}
+
+	if err := c.addNewUser(message); err != nil {
+		return e.Wrap("save new user failed with an error: ", err)
+	}
+
+	msg := msgLangSet
+	if lang == langAuto {
+		lang = ""
+		msg = msgLangAuto
+	}
+
+	if err := c.Event.SetLanguage(ownerID(message.Chat), lang); err != nil {
+		return e.Wrap("set language failed with an error: ", err)
+	}
+
+	return c.sendText(message, msg)
+}
+
+// languageFromCode выбирает язык по language_code из Telegram, например
+// ru или en-US. Без кода остается язык по умолчанию, а языки, которых нет
+// в каталоге, получают английский.
+func languageFromCode(code string) string {
+	if code == "" {
+		return defaultLang
+	}
+
+	lang := strings.ToLower(strings.SplitN(code, "-", 2)[0])
+	if _, ok := catalog[lang]; ok {
+		return lang
+	}
+
+	return langEN
+}
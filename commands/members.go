@@ -0,0 +1,150 @@
+package commands
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/EfimoffN/authorBot/events"
+	"github.com/EfimoffN/authorBot/lib/e"
+	"github.com/EfimoffN/authorBot/lib/logger"
+	"github.com/EfimoffN/authorBot/metrics"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+// Состояния участника чата в Telegram.
+const (
+	memberLeft   = "left"
+	memberKicked = "kicked"
+)
+
+// Метки метрик для обновлений, которые не являются новыми сообщениями.
+const (
+	labelChatMember = "chat_member"
+	labelEdited     = "edited"
+)
+
+// ChatMemberUpdated — обновление my_chat_member: бота заблокировали или
+// разблокировали в личном чате, добавили в группу или удалили из нее. В
+// используемой версии tgbotapi такого обновления нет.
+type ChatMemberUpdated struct {
+	Chat          tgbotapi.Chat       `json:"chat"`
+	From          tgbotapi.User       `json:"from"`
+	Date          int                 `json:"date"`
+	OldChatMember tgbotapi.ChatMember `json:"old_chat_member"`
+	NewChatMember tgbotapi.ChatMember `json:"new_chat_member"`
+}
+
+// ChatMemberFields возвращает поля журнала для изменения участия бота в
+// чате.
+func ChatMemberFields(update *ChatMemberUpdated) []interface{} {
+	return []interface{}{
+		logger.KeyChatID, update.Chat.ID,
+		logger.KeyUserID, update.From.ID,
+		logger.KeyCommand, labelChatMember,
+		"status", update.NewChatMember.Status,
+	}
+}
+
+// DoChatMember сохраняет, что бота заблокировали, разблокировали, добавили
+// в группу или удалили из нее либо из канала, и учитывает обновление в
+// метриках.
+func (c *Commands) DoChatMember(update *ChatMemberUpdated) error {
+	started := time.Now()
+	err := c.doChatMember(update)
+	metrics.ObserveUpdate(labelChatMember, started, err)
+
+	return err
+}
+
+func (c *Commands) doChatMember(update *ChatMemberUpdated) error {
+	present := isMember(update.NewChatMember)
+
+	// Каналы служат только для оповещений, подписок у них нет. Если бота
+	// удалили из канала, оповещения возвращаются в чаты владельцев.
+	if update.Chat.IsChannel() {
+		if present {
+			return nil
+		}
+
+		if err := c.Event.LeaveChannel(update.Chat.ID); err != nil {
+			return e.Wrap("leave channel failed with an error: ", err)
+		}
+
+		return nil
+	}
+
+	if update.Chat.IsPrivate() || !present {
+		if err := c.Event.SetBlocked(ownerID(&update.Chat), !present); err != nil {
+			return e.Wrap("set blocked failed with an error: ", err)
+		}
+
+		return nil
+	}
+
+	if err := c.Event.JoinChat(update.Chat.Title, ownerID(&update.Chat), update.Chat.ID); err != nil {
+		return e.Wrap("join chat failed with an error: ", err)
+	}
+
+	return nil
+}
+
+// isMember сообщает, остается ли бот в чате: его не удалили из группы и не
+// заблокировали в личном чате.
+func isMember(member tgbotapi.ChatMember) bool {
+	return member.Status != memberLeft && member.Status != memberKicked
+}
+
+// DoEditedMessage обрабатывает исправленное сообщение. Бот отвечает только
+// на ссылки, которых пользователь еще не отслеживает, например исправленные
+// после опечатки: ссылки из исходного сообщения уже сохранены. Команды из
+// исправленных сообщений повторно не выполняются, поэтому в группах, где
+// ссылки добавляются только командой /add, исправления не обрабатываются.
+func (c *Commands) DoEditedMessage(message *tgbotapi.Message) error {
+	text := strings.TrimSpace(message.Text)
+	if !message.Chat.IsPrivate() || message.Document != nil || !isAddCmd(text) || isRemoveLink(text) {
+		return nil
+	}
+
+	started := time.Now()
+
+	links, err := c.untrackedLinks(ownerID(message.Chat), extractLinks(message))
+	if err != nil {
+		metrics.ObserveUpdate(labelEdited, started, err)
+
+		return err
+	}
+
+	if len(links) == 0 {
+		return nil
+	}
+
+	// Отвечаем только о новых ссылках, как если бы их прислали заново.
+	edited := *message
+	edited.Text = strings.Join(links, "\n")
+	edited.Entities = nil
+
+	err = c.doCommand(&edited)
+	metrics.ObserveUpdate(labelEdited, started, err)
+
+	return err
+}
+
+// untrackedLinks возвращает ссылки, которых пользователь еще не отслеживает.
+func (c *Commands) untrackedLinks(userID int, links []string) ([]string, error) {
+	untracked := []string{}
+
+	for _, link := range links {
+		_, err := c.Event.GetSubscription(userID, link)
+
+		switch {
+		case err == nil:
+		case errors.Is(err, events.ErrLinkNotDB), errors.Is(err, events.ErrRefNotFound):
+			untracked = append(untracked, link)
+		default:
+			return nil, e.Wrap("get subscription failed with an error: ", err)
+		}
+	}
+
+	return untracked, nil
+}
@@ -0,0 +1,135 @@
+package commands
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+func Test_isMember(t *testing.T) {
+	tests := []struct {
+		status string
+		want   bool
+	}{
+		{status: "creator", want: true},
+		{status: "administrator", want: true},
+		{status: "member", want: true},
+		{status: "restricted", want: true},
+		{status: memberLeft, want: false},
+		{status: memberKicked, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			if got := isMember(tgbotapi.ChatMember{Status: tt.status}); got != tt.want {
+				t.Errorf("isMember(%q) = %v, want %v", tt.status, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_doChatMember(t *testing.T) {
+	private := tgbotapi.Chat{ID: 42, Type: "private"}
+	group := tgbotapi.Chat{ID: -100, Type: "supergroup", Title: "Читатели"}
+	channel := tgbotapi.Chat{ID: -200, Type: "channel", Title: "Новинки"}
+
+	tests := []struct {
+		name   string
+		chat   tgbotapi.Chat
+		status string
+		want   []string
+	}{
+		{name: "blocked in private chat", chat: private, status: memberKicked, want: []string{"blocked 42 true"}},
+		{name: "unblocked in private chat", chat: private, status: "member", want: []string{"blocked 42 false"}},
+		{name: "added to group", chat: group, status: "member", want: []string{"join Читатели -100 -100"}},
+		{name: "removed from group", chat: group, status: memberLeft, want: []string{"blocked -100 true"}},
+		{name: "added to channel", chat: channel, status: "administrator", want: nil},
+		{name: "removed from channel", chat: channel, status: memberKicked, want: []string{"leave -200"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := &fakeEvent{}
+			c := newTestCommands(event, context.Background())
+
+			update := &ChatMemberUpdated{Chat: tt.chat, NewChatMember: tgbotapi.ChatMember{Status: tt.status}}
+			if err := c.doChatMember(update); err != nil {
+				t.Fatalf("doChatMember() error = %v", err)
+			}
+
+			if !reflect.DeepEqual(event.calls, tt.want) {
+				t.Errorf("doChatMember() calls = %v, want %v", event.calls, tt.want)
+			}
+		})
+	}
+}
+
+func Test_DoEditedMessage(t *testing.T) {
+	const (
+		oldLink = "https://author.today/work/1"
+		newLink = "https://author.today/work/2"
+	)
+
+	tests := []struct {
+		name      string
+		text      string
+		wantCalls []string
+		wantReply string
+	}{
+		{
+			name:      "typo fixed in the only link",
+			text:      newLink,
+			wantCalls: []string{"add 42 " + newLink},
+			wantReply: locale(langRU).T(msgLinkSaved),
+		},
+		{
+			name:      "link added to tracked ones",
+			text:      oldLink + "\n" + newLink,
+			wantCalls: []string{"add 42 " + newLink},
+			wantReply: locale(langRU).T(msgLinkSaved),
+		},
+		{
+			name: "only tracked links",
+			text: "Посмотри " + oldLink,
+		},
+		{
+			name: "command",
+			text: "/add " + newLink,
+		},
+		{
+			name: "no links",
+			text: "Просто текст",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := &fakeEvent{tracked: map[string]bool{oldLink: true}}
+			tg := &fakeTelegram{}
+
+			c := newTestCommands(event, context.Background())
+			c.BotAPI = newTestBot(tg)
+
+			message := &tgbotapi.Message{
+				Text: tt.text,
+				Chat: &tgbotapi.Chat{ID: 42, Type: "private"},
+				From: &tgbotapi.User{ID: 42},
+			}
+
+			if err := c.DoEditedMessage(message); err != nil {
+				t.Fatalf("DoEditedMessage() error = %v", err)
+			}
+
+			if !reflect.DeepEqual(event.calls, tt.wantCalls) {
+				t.Errorf("DoEditedMessage() calls = %v, want %v", event.calls, tt.wantCalls)
+			}
+
+			if got := strings.Join(tg.texts, "|"); got != tt.wantReply {
+				t.Errorf("DoEditedMessage() replies = %q, want %q", got, tt.wantReply)
+			}
+		})
+	}
+}
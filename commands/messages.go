@@ -1,23 +1,263 @@
 package commands
 
-const msgHelp = `Я могу оповещать об изменениях на партале author.today.
+const helpRU = `Я могу оповещать об изменениях на партале author.today.
 
 Я сохраню указанную ссылку и буду оповещать тебя об изменения, что бы ты ничего не пропустил.
 
 Могу отслеживать следующие парметры:
 - изменения в выбранной книге;
+- новые произведения и циклы автора (ссылка на профиль вида https://author.today/u/логин);
+- новые тома цикла и смену их статуса (ссылка вида https://author.today/work/series/...);
 - блог автора;
 - комментарии автора;
 
 Для того что бы сохранить ссылку для отслеживания, просто отправь мне эту ссылку.
+Можно прислать сразу несколько ссылок одним сообщением, например, списком.
 
 Если необходимо удалить ссылку, отпрвь мне ссылку с префиксом /rmv и ссылка. 
 Будет выглядеть так: /rmv https://...
 
-Для того что бы посмотреть все сохраненные ссылки отправь /all.`
+Для того что бы посмотреть все сохраненные ссылки отправь /all.
 
-const msgHello = "Доброго времени суток! \n\n" + msgHelp
+Что бы получать оповещения об изменении цены книги, отправь /price и ссылку.
+Если нужно узнать только о падении цены ниже заданной, добавь порог: /price https://... 100
+Отключить оповещения о цене: /noprice https://...
 
+Оповещения о смене статуса книги (завершена, заморожена, разморожена, скрыта) приходят отдельно от оповещений о новых главах.
+Отключить или включить их: /nostatus https://... и /status https://...
+Если ждешь только завершения книги, отключи главы: /nochapters https://... (включить обратно: /chapters https://...)
+
+Если оповещений слишком много, их можно получать одной сводкой раз в день или раз в неделю в выбранный час:
+/digest daily 9 или /digest weekly 20. Вернуть мгновенные оповещения: /digest off
+
+Тихие часы, когда оповещения не приходят, а копятся до их окончания: /quiet 23:00-08:00 (отключить: /quiet off).
+Час сводки, тихие часы и время в сообщениях считаются в твоем часовом поясе, по умолчанию московском. Сменить его: /tz Europe/Moscow
+
+Выбрать, какие оповещения приходят по ссылке (главы, цена, статус, аннотация, блог, комментарии): /filter https://...
+Записи блога и комментарии можно отбирать по словам: /include https://... том, продолжение
+Или скрывать записи с определенными словами: /exclude https://... розыгрыш
+Команда без слов сбрасывает фильтр.
+
+Поставить ссылку на паузу: /pause https://... (вернуть: /resume https://...)
+Отложить оповещения на время: /snooze 3d https://... или все сразу: /snooze 8h
+Длительность задается в минутах, часах, днях или неделях: 30m, 8h, 3d, 1w. Снять общую отсрочку: /resume
+
+Выгрузить подписки файлом, например, чтобы перенести их в другой аккаунт: /export (или /export csv).
+Загрузить подписки из такого файла: просто пришли мне его документом.
+
+Удалить все свои данные и подписки и перестать получать оповещения: /stop
+
+Язык сообщений: /lang ru, /lang en или /lang auto, чтобы выбирать его по настройкам Telegram.
+
+Меня можно добавить в группу: у группы будет общий список подписок, а оповещения будут приходить в нее. Ссылки в группе добавляются командой /add https://..., а файл из /export загружается с подписью /import; менять подписки могут только администраторы группы.
+Оповещения можно получать в своем канале. Добавь меня в канал администратором с правом публиковать сообщения и отправь /channel @имя_канала. Вернуть оповещения в чат: /channel off`
+
+// Ключи сообщений бота. Тексты на каждом языке лежат в каталоге catalog.
+const (
+	msgHelp              msgKey = "help"
+	msgHello             msgKey = "hello"
+	msgLinkSaved         msgKey = "link_saved"
+	msgLinkExists        msgKey = "link_exists"
+	msgLinkFailed        msgKey = "link_failed"
+	msgLinkRemoved       msgKey = "link_removed"
+	msgNoLinks           msgKey = "no_links"
+	msgLinksHeader       msgKey = "links_header"
+	msgUnknownCommand    msgKey = "unknown_command"
+	msgBulkHeader        msgKey = "bulk_header"
+	msgAddUsage          msgKey = "add_usage"
+	msgRemoveUsage       msgKey = "remove_usage"
+	msgAdminsOnly        msgKey = "admins_only"
+	msgChannelUsage      msgKey = "channel_usage"
+	msgChannelSet        msgKey = "channel_set"
+	msgChannelOff        msgKey = "channel_off"
+	msgChannelNoBot      msgKey = "channel_no_bot"
+	msgLinkNotTracked    msgKey = "link_not_tracked"
+	msgPriceUsage        msgKey = "price_usage"
+	msgPriceOn           msgKey = "price_on"
+	msgPriceBelow        msgKey = "price_below"
+	msgPriceOff          msgKey = "price_off"
+	msgAlertUsage        msgKey = "alert_usage"
+	msgStatusOn          msgKey = "status_on"
+	msgStatusOff         msgKey = "status_off"
+	msgChaptersOn        msgKey = "chapters_on"
+	msgChaptersOff       msgKey = "chapters_off"
+	msgSeriesItem        msgKey = "series_item"
+	msgDigestUsage       msgKey = "digest_usage"
+	msgDigestDaily       msgKey = "digest_daily"
+	msgDigestWeekly      msgKey = "digest_weekly"
+	msgDigestOff         msgKey = "digest_off"
+	msgQuietUsage        msgKey = "quiet_usage"
+	msgQuietOn           msgKey = "quiet_on"
+	msgQuietOff          msgKey = "quiet_off"
+	msgTimezoneUsage     msgKey = "timezone_usage"
+	msgTimezoneSet       msgKey = "timezone_set"
+	msgLastCheck         msgKey = "last_check"
+	msgFilterUsage       msgKey = "filter_usage"
+	msgKeywordsUsage     msgKey = "keywords_usage"
+	msgKeywordsSet       msgKey = "keywords_set"
+	msgKeywordsNone      msgKey = "keywords_none"
+	msgFilterSaved       msgKey = "filter_saved"
+	msgPauseUsage        msgKey = "pause_usage"
+	msgPaused            msgKey = "paused"
+	msgResumed           msgKey = "resumed"
+	msgResumedAll        msgKey = "resumed_all"
+	msgSnoozeUsage       msgKey = "snooze_usage"
+	msgSnoozedAll        msgKey = "snoozed_all"
+	msgSnoozedLink       msgKey = "snoozed_link"
+	msgAllSnoozed        msgKey = "all_snoozed"
+	msgPausedMark        msgKey = "paused_mark"
+	msgSnoozedMark       msgKey = "snoozed_mark"
+	msgStopAsk           msgKey = "stop_ask"
+	msgStopYes           msgKey = "stop_yes"
+	msgStopNo            msgKey = "stop_no"
+	msgStopDone          msgKey = "stop_done"
+	msgStopCancelled     msgKey = "stop_cancelled"
+	msgExportUsage       msgKey = "export_usage"
+	msgExportEmpty       msgKey = "export_empty"
+	msgExportDone        msgKey = "export_done"
+	msgImportUsage       msgKey = "import_usage"
+	msgImportBadFile     msgKey = "import_bad_file"
+	msgImportTooLarge    msgKey = "import_too_large"
+	msgImportDone        msgKey = "import_done"
+	msgChannelNotFound   msgKey = "channel_not_found"
+	msgChannelNotAdmin   msgKey = "channel_not_admin"
+	msgTimezoneUnknown   msgKey = "timezone_unknown"
+	msgUnsupportedSource msgKey = "unsupported_source"
+	msgUnsupportedLink   msgKey = "unsupported_link"
+	msgFilterMenu        msgKey = "filter_menu"
+	msgFilterChapters    msgKey = "filter_chapters"
+	msgFilterPrice       msgKey = "filter_price"
+	msgFilterStatus      msgKey = "filter_status"
+	msgFilterAnnotation  msgKey = "filter_annotation"
+	msgFilterBlog        msgKey = "filter_blog"
+	msgFilterComments    msgKey = "filter_comments"
+	msgFilterNoInclude   msgKey = "filter_no_include"
+	msgFilterNoExclude   msgKey = "filter_no_exclude"
+	msgLangUsage         msgKey = "lang_usage"
+	msgLangSet           msgKey = "lang_set"
+	msgLangAuto          msgKey = "lang_auto"
+	msgStats             msgKey = "stats"
+	msgBroadcastUsage    msgKey = "broadcast_usage"
+	msgBroadcastProgress msgKey = "broadcast_progress"
+	msgBroadcastDone     msgKey = "broadcast_done"
+	msgBanUsage          msgKey = "ban_usage"
+	msgBanned            msgKey = "banned"
+	msgUnbanned          msgKey = "unbanned"
+	msgUserNotFound      msgKey = "user_not_found"
+	msgCheckUsage        msgKey = "check_usage"
+	msgCheckDone         msgKey = "check_done"
+	msgCheckFailed       msgKey = "check_failed"
+)
+
+// Отметки включенных и выключенных оповещений на кнопках меню настроек.
 const (
-	msgUnknownCommand = "Неизвестная команда."
+	msgFilterOn  = "✅"
+	msgFilterOff = "❌"
 )
+
+// msgBulkItem — строка сводки о добавлении нескольких ссылок: ссылка и
+// результат.
+const msgBulkItem = "%s\n%s\n\n"
+
+var messagesRU = map[msgKey]string{
+	msgHelp:              helpRU,
+	msgHello:             "Доброго времени суток! \n\n" + helpRU,
+	msgLinkSaved:         "Ссылка сохранена для отслеживания.",
+	msgLinkExists:        "Такая ссылка уже добавлялась.",
+	msgLinkFailed:        "Не удалось сохранить ссылку, попробуй позже.",
+	msgLinkRemoved:       "Ссылка больше не отслеживается.",
+	msgNoLinks:           "У вас нет отслеживаемых ссылок",
+	msgLinksHeader:       "Ваши отслеживаемые ссылки:\n",
+	msgUnknownCommand:    "Неизвестная команда.",
+	msgBulkHeader:        "Ссылок в сообщении: %d\n\n",
+	msgAddUsage:          "Укажи одну или несколько ссылок после команды: /add https://...",
+	msgRemoveUsage:       "Укажи ссылку после команды: /rmv https://...",
+	msgAdminsOnly:        "Менять подписки группы могут только ее администраторы.",
+	msgChannelUsage:      "Укажи канал: /channel @имя_канала или верни оповещения в чат: /channel off",
+	msgChannelSet:        "Оповещения будут приходить в канал.",
+	msgChannelOff:        "Оповещения снова приходят в этот чат.",
+	msgChannelNoBot:      "Добавь меня в канал администратором с правом публиковать сообщения и повтори команду.",
+	msgLinkNotTracked:    "Такая ссылка не отслеживается. Сначала отправь мне ссылку.",
+	msgPriceUsage:        "Укажи ссылку и, если нужно, порог цены: /price https://... 100",
+	msgPriceOn:           "Буду оповещать об изменении цены.",
+	msgPriceBelow:        "Оповещу, когда цена опустится ниже %d ₽.",
+	msgPriceOff:          "Оповещения о цене отключены.",
+	msgAlertUsage:        "Укажи ссылку после команды, например: /status https://...",
+	msgStatusOn:          "Буду оповещать о смене статуса книги.",
+	msgStatusOff:         "Оповещения о смене статуса книги отключены.",
+	msgChaptersOn:        "Буду оповещать о новых главах.",
+	msgChaptersOff:       "Оповещения о новых главах отключены.",
+	msgSeriesItem:        "Цикл «%s», томов: %d",
+	msgDigestUsage:       "Укажи режим и, если нужно, час отправки: /digest daily 9, /digest weekly 20 или /digest off",
+	msgDigestDaily:       "Буду присылать сводку изменений раз в день в %d:00.",
+	msgDigestWeekly:      "Буду присылать сводку изменений раз в неделю в %d:00.",
+	msgDigestOff:         "Оповещения снова приходят сразу.",
+	msgQuietUsage:        "Укажи тихие часы в виде /quiet 23:00-08:00 или отключи их: /quiet off",
+	msgQuietOn:           "Тихие часы: с %s до %s. Оповещения за это время придут после их окончания.",
+	msgQuietOff:          "Тихие часы отключены.",
+	msgTimezoneUsage:     "Укажи часовой пояс, например: /tz Europe/Moscow",
+	msgTimezoneSet:       "Часовой пояс: %s.",
+	msgLastCheck:         " (проверено %s)",
+	msgFilterUsage:       "Укажи ссылку после команды, например: /filter https://...",
+	msgKeywordsUsage:     "Укажи ссылку и слова через запятую, например: /include https://... том, продолжение",
+	msgKeywordsSet:       "Слова для записей блога и комментариев.\nПоказывать только с: %s\nСкрывать с: %s",
+	msgKeywordsNone:      "—",
+	msgFilterSaved:       "Сохранено.",
+	msgPauseUsage:        "Укажи ссылку после команды, например: /pause https://...",
+	msgPaused:            "Ссылка на паузе, оповещений по ней не будет до /resume.",
+	msgResumed:           "Оповещения по ссылке снова включены.",
+	msgResumedAll:        "Оповещения снова включены.",
+	msgSnoozeUsage:       "Укажи длительность и, если нужно, ссылку: /snooze 3d https://... или /snooze 8h",
+	msgSnoozedAll:        "Все оповещения отложены до %s.",
+	msgSnoozedLink:       "Оповещения по ссылке отложены до %s.",
+	msgAllSnoozed:        "Все оповещения отложены до %s.\n\n",
+	msgPausedMark:        " ⏸ на паузе",
+	msgSnoozedMark:       " ⏸ отложено до %s",
+	msgStopAsk:           "Удалить все твои подписки и настройки? Оповещения перестанут приходить, восстановить данные будет нельзя.",
+	msgStopYes:           "Да, удалить",
+	msgStopNo:            "Отмена",
+	msgStopDone:          "Все твои данные удалены. Если захочешь вернуться, отправь /start.",
+	msgStopCancelled:     "Удаление отменено.",
+	msgExportUsage:       "Укажи формат файла: /export, /export json или /export csv",
+	msgExportEmpty:       "У вас нет отслеживаемых ссылок, выгружать нечего.",
+	msgExportDone:        "Подписок в файле: %d. Чтобы загрузить их в другом аккаунте, пришли этот файл боту.",
+	msgImportUsage:       "Пришли мне документом файл JSON или CSV, который выгружен командой /export.",
+	msgImportBadFile:     "Не получилось прочитать файл. Пришли файл JSON или CSV, выгруженный командой /export.",
+	msgImportTooLarge:    "Файл слишком большой.",
+	msgImportDone:        "Загрузка завершена.\nДобавлено: %d\nУже отслеживались: %d\nНекорректные: %d\nНе удалось добавить: %d",
+	msgChannelNotFound:   "Не нашел такой канал. Укажи его в виде @имя_канала.",
+	msgChannelNotAdmin:   "Направить оповещения в канал может только его администратор.",
+	msgTimezoneUnknown:   "Не знаю такого часового пояса. Укажи его в виде Europe/Moscow или Asia/Yekaterinburg.",
+	msgUnsupportedSource: "Этот сайт не поддерживается. Поддерживаемые площадки: %s.",
+	msgUnsupportedLink:   "Такие ссылки пока не отслеживаются. Пришли ссылку на книгу, профиль автора или цикл.",
+	msgFilterMenu: `Оповещения по ссылке:
+%s
+
+Записи блога и комментарии.
+Показывать только с: %s
+Скрывать с: %s
+
+Слова задаются командами /include и /exclude.`,
+	msgFilterChapters:    "Главы",
+	msgFilterPrice:       "Цена",
+	msgFilterStatus:      "Статус",
+	msgFilterAnnotation:  "Аннотация",
+	msgFilterBlog:        "Блог",
+	msgFilterComments:    "Комментарии",
+	msgFilterNoInclude:   "Сбросить «показывать»",
+	msgFilterNoExclude:   "Сбросить «скрывать»",
+	msgLangUsage:         "Укажи язык: /lang ru, /lang en или /lang auto, чтобы выбирать его по настройкам Telegram.",
+	msgLangSet:           "Язык сообщений: русский.",
+	msgLangAuto:          "Язык сообщений выбирается по настройкам Telegram.",
+	msgStats:             "Пользователей: %d (заблокировано: %d, заблокировали бота: %d)\nСсылок: %d\nПодписок: %d\nПроверок за час: %d\nОшибок за час: %d",
+	msgBroadcastUsage:    "Напиши текст рассылки после команды: /broadcast текст",
+	msgBroadcastProgress: "Рассылка: отправлено %d из %d.",
+	msgBroadcastDone:     "Рассылка завершена.\nДоставлено: %d\nНе доставлено: %d",
+	msgBanUsage:          "Укажи идентификатор пользователя: /ban 123456",
+	msgBanned:            "Пользователь %d заблокирован.",
+	msgUnbanned:          "Пользователь %d разблокирован.",
+	msgUserNotFound:      "Такого пользователя нет.",
+	msgCheckUsage:        "Укажи ссылку после команды: /checknow https://...",
+	msgCheckDone:         "Ссылка проверена, оповещения об изменениях отправлены.",
+	msgCheckFailed:       "Проверка не удалась: %s",
+}
@@ -0,0 +1,156 @@
+package commands
+
+const helpEN = `I can notify you about updates on author.today.
+
+I will save the link you send and let you know when something changes, so you don't miss anything.
+
+I can track:
+- updates to a book;
+- new works and series of an author (a profile link like https://author.today/u/login);
+- new volumes of a series and their status (a link like https://author.today/work/series/...);
+- the author's blog;
+- the author's comments;
+
+To track a link, just send it to me.
+You can send several links in one message, for example as a list.
+
+To stop tracking a link, send /rmv followed by the link.
+It looks like this: /rmv https://...
+
+To see all saved links, send /all.
+
+To get notified about book price changes, send /price and the link.
+To hear only about the price falling below a threshold, add it: /price https://... 100
+Turn price notifications off: /noprice https://...
+
+Book status changes (finished, frozen, unfrozen, hidden) are notified separately from new chapters.
+Turn them off or on: /nostatus https://... and /status https://...
+If you are only waiting for the book to be finished, turn chapters off: /nochapters https://... (back on: /chapters https://...)
+
+If there are too many notifications, get them as a single digest once a day or once a week at a chosen hour:
+/digest daily 9 or /digest weekly 20. Back to instant notifications: /digest off
+
+Quiet hours, when notifications are held until they end: /quiet 23:00-08:00 (turn off: /quiet off).
+The digest hour, quiet hours and times in messages use your time zone, Moscow by default. Change it: /tz Europe/Moscow
+
+Choose which notifications a link sends (chapters, price, status, annotation, blog, comments): /filter https://...
+Blog posts and comments can be filtered by words: /include https://... volume, sequel
+Or hidden when they contain certain words: /exclude https://... giveaway
+The command without words resets the filter.
+
+Pause a link: /pause https://... (undo: /resume https://...)
+Snooze notifications for a while: /snooze 3d https://... or all at once: /snooze 8h
+The duration is in minutes, hours, days or weeks: 30m, 8h, 3d, 1w. Cancel the snooze for all: /resume
+
+Download your subscriptions as a file, for example to move them to another account: /export (or /export csv).
+Load subscriptions from such a file: just send it to me as a document.
+
+Delete all your data and subscriptions and stop getting notifications: /stop
+
+Add me to a group to share one subscription list; notifications will be posted there. In a group, links are added with /add https://... and a file from /export is loaded with the caption /import; only group admins can change subscriptions.
+Notifications can go to your channel. Add me to the channel as an admin allowed to post messages and send /channel @channel_name. Back to this chat: /channel off
+
+Message language: /lang ru, /lang en, or /lang auto to follow your Telegram settings.`
+
+var messagesEN = map[msgKey]string{
+	msgHelp:              helpEN,
+	msgHello:             "Hello! \n\n" + helpEN,
+	msgLinkSaved:         "The link is saved for tracking.",
+	msgLinkExists:        "This link has already been added.",
+	msgLinkFailed:        "Could not save the link, try again later.",
+	msgLinkRemoved:       "The link is no longer tracked.",
+	msgNoLinks:           "You have no tracked links",
+	msgLinksHeader:       "Your tracked links:\n",
+	msgUnknownCommand:    "Unknown command.",
+	msgBulkHeader:        "Links in the message: %d\n\n",
+	msgAddUsage:          "Put one or more links after the command: /add https://...",
+	msgRemoveUsage:       "Put the link after the command: /rmv https://...",
+	msgAdminsOnly:        "Only group admins can change the group's subscriptions.",
+	msgChannelUsage:      "Specify the channel: /channel @channel_name, or bring notifications back to this chat: /channel off",
+	msgChannelSet:        "Notifications will be posted to the channel.",
+	msgChannelOff:        "Notifications come to this chat again.",
+	msgChannelNoBot:      "Add me to the channel as an admin allowed to post messages and repeat the command.",
+	msgLinkNotTracked:    "This link is not tracked. Send me the link first.",
+	msgPriceUsage:        "Specify the link and, if needed, a price threshold: /price https://... 100",
+	msgPriceOn:           "I will notify you about price changes.",
+	msgPriceBelow:        "I will notify you when the price falls below %d ₽.",
+	msgPriceOff:          "Price notifications are off.",
+	msgAlertUsage:        "Put the link after the command, for example: /status https://...",
+	msgStatusOn:          "I will notify you about book status changes.",
+	msgStatusOff:         "Book status notifications are off.",
+	msgChaptersOn:        "I will notify you about new chapters.",
+	msgChaptersOff:       "New chapter notifications are off.",
+	msgSeriesItem:        "Series \"%s\", volumes: %d",
+	msgDigestUsage:       "Specify the mode and, if needed, the hour: /digest daily 9, /digest weekly 20 or /digest off",
+	msgDigestDaily:       "I will send a digest of updates once a day at %d:00.",
+	msgDigestWeekly:      "I will send a digest of updates once a week at %d:00.",
+	msgDigestOff:         "Notifications come instantly again.",
+	msgQuietUsage:        "Specify quiet hours as /quiet 23:00-08:00 or turn them off: /quiet off",
+	msgQuietOn:           "Quiet hours: from %s to %s. Notifications during this time will arrive when they end.",
+	msgQuietOff:          "Quiet hours are off.",
+	msgTimezoneUsage:     "Specify the time zone, for example: /tz Europe/Moscow",
+	msgTimezoneSet:       "Time zone: %s.",
+	msgLastCheck:         " (checked %s)",
+	msgFilterUsage:       "Put the link after the command, for example: /filter https://...",
+	msgKeywordsUsage:     "Specify the link and comma-separated words, for example: /include https://... volume, sequel",
+	msgKeywordsSet:       "Words for blog posts and comments.\nShow only with: %s\nHide with: %s",
+	msgKeywordsNone:      "—",
+	msgFilterSaved:       "Saved.",
+	msgPauseUsage:        "Put the link after the command, for example: /pause https://...",
+	msgPaused:            "The link is paused, no notifications until /resume.",
+	msgResumed:           "Notifications for the link are on again.",
+	msgResumedAll:        "Notifications are on again.",
+	msgSnoozeUsage:       "Specify the duration and, if needed, the link: /snooze 3d https://... or /snooze 8h",
+	msgSnoozedAll:        "All notifications are snoozed until %s.",
+	msgSnoozedLink:       "Notifications for the link are snoozed until %s.",
+	msgAllSnoozed:        "All notifications are snoozed until %s.\n\n",
+	msgPausedMark:        " ⏸ paused",
+	msgSnoozedMark:       " ⏸ snoozed until %s",
+	msgStopAsk:           "Delete all your subscriptions and settings? Notifications will stop and the data cannot be restored.",
+	msgStopYes:           "Yes, delete",
+	msgStopNo:            "Cancel",
+	msgStopDone:          "All your data is deleted. If you want to come back, send /start.",
+	msgStopCancelled:     "Deletion cancelled.",
+	msgExportUsage:       "Specify the file format: /export, /export json or /export csv",
+	msgExportEmpty:       "You have no tracked links, nothing to export.",
+	msgExportDone:        "Subscriptions in the file: %d. To load them in another account, send this file to the bot.",
+	msgImportUsage:       "Send me a JSON or CSV file exported with /export as a document.",
+	msgImportBadFile:     "Could not read the file. Send a JSON or CSV file exported with /export.",
+	msgImportTooLarge:    "The file is too large.",
+	msgImportDone:        "Import finished.\nAdded: %d\nAlready tracked: %d\nInvalid: %d\nFailed: %d",
+	msgChannelNotFound:   "Channel not found. Specify it as @channel_name.",
+	msgChannelNotAdmin:   "Only a channel admin can send notifications to the channel.",
+	msgTimezoneUnknown:   "Unknown time zone. Specify it like Europe/Moscow or Asia/Yekaterinburg.",
+	msgUnsupportedSource: "This site is not supported. Supported platforms: %s.",
+	msgUnsupportedLink:   "Such links are not tracked yet. Send a link to a book, an author profile or a series.",
+	msgFilterMenu: `Notifications for the link:
+%s
+
+Blog posts and comments.
+Show only with: %s
+Hide with: %s
+
+Words are set with /include and /exclude.`,
+	msgFilterChapters:    "Chapters",
+	msgFilterPrice:       "Price",
+	msgFilterStatus:      "Status",
+	msgFilterAnnotation:  "Annotation",
+	msgFilterBlog:        "Blog",
+	msgFilterComments:    "Comments",
+	msgFilterNoInclude:   "Reset \"show\"",
+	msgFilterNoExclude:   "Reset \"hide\"",
+	msgLangUsage:         "Specify the language: /lang ru, /lang en, or /lang auto to follow your Telegram settings.",
+	msgLangSet:           "Message language: English.",
+	msgLangAuto:          "The message language follows your Telegram settings.",
+	msgStats:             "Users: %d (banned: %d, blocked the bot: %d)\nLinks: %d\nSubscriptions: %d\nChecks in the last hour: %d\nFailures in the last hour: %d",
+	msgBroadcastUsage:    "Put the broadcast text after the command: /broadcast text",
+	msgBroadcastProgress: "Broadcast: %d of %d sent.",
+	msgBroadcastDone:     "Broadcast finished.\nDelivered: %d\nNot delivered: %d",
+	msgBanUsage:          "Specify the user ID: /ban 123456",
+	msgBanned:            "User %d is banned.",
+	msgUnbanned:          "User %d is unbanned.",
+	msgUserNotFound:      "There is no such user.",
+	msgCheckUsage:        "Put the link after the command: /checknow https://...",
+	msgCheckDone:         "The link is checked, change notifications are sent.",
+	msgCheckFailed:       "The check failed: %s",
+}
@@ -0,0 +1,86 @@
+package commands
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var verbRegexp = regexp.MustCompile(`%[-+# 0]*\d*[a-zA-Z]`)
+
+func Test_catalog(t *testing.T) {
+	keys := map[msgKey]bool{}
+
+	for _, messages := range catalog {
+		for key := range messages {
+			keys[key] = true
+		}
+	}
+
+	for lang, messages := range catalog {
+		for key := range keys {
+			text, ok := messages[key]
+			if !ok {
+				t.Errorf("catalog[%q] has no message %q", lang, key)
+
+				continue
+			}
+
+			if text == "" {
+				t.Errorf("catalog[%q][%q] is empty", lang, key)
+			}
+
+			// Ответы уходят в разметке HTML.
+			if strings.ContainsAny(text, "<>&") {
+				t.Errorf("catalog[%q][%q] has unescaped HTML", lang, key)
+			}
+
+			want := verbRegexp.FindAllString(catalog[defaultLang][key], -1)
+			if got := verbRegexp.FindAllString(text, -1); !reflect.DeepEqual(got, want) {
+				t.Errorf("catalog[%q][%q] verbs = %v, want %v", lang, key, got, want)
+			}
+		}
+	}
+}
+
+func Test_localeHTML(t *testing.T) {
+	l := locale(langRU)
+
+	got := l.HTML(msgSeriesItem, "<b>Тени</b> & свет", 3)
+	if want := "Цикл «&lt;b&gt;Тени&lt;/b&gt; &amp; свет», томов: 3"; got != want {
+		t.Errorf("HTML() = %q, want %q", got, want)
+	}
+}
+
+func Test_languageFromCode(t *testing.T) {
+	tests := []struct {
+		code string
+		want string
+	}{
+		{code: "", want: langRU},
+		{code: "ru", want: langRU},
+		{code: "en", want: langEN},
+		{code: "en-US", want: langEN},
+		{code: "RU-ru", want: langRU},
+		{code: "de", want: langEN},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			if got := languageFromCode(tt.code); got != tt.want {
+				t.Errorf("languageFromCode(%q) = %q, want %q", tt.code, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_localeT(t *testing.T) {
+	if got, want := locale(langEN).T(msgPriceBelow, 100), "I will notify you when the price falls below 100 ₽."; got != want {
+		t.Errorf("T() = %q, want %q", got, want)
+	}
+
+	if got, want := locale("xx").T(msgFilterSaved), messagesRU[msgFilterSaved]; got != want {
+		t.Errorf("T() for unknown language = %q, want %q", got, want)
+	}
+}
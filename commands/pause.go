@@ -0,0 +1,150 @@
+package commands
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/EfimoffN/authorBot/events"
+	"github.com/EfimoffN/authorBot/lib/e"
+	"github.com/EfimoffN/authorBot/lib/tz"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+// noSnooze — время, записью которого снимается отсрочка.
+var noSnooze = time.Unix(0, 0).UTC()
+
+var snoozeRegexp = regexp.MustCompile(`^(\d+)([mhdw])$`)
+
+// pauseLink ставит подписку на паузу или снимает с нее вместе с отсрочкой.
+func (c *Commands) pauseLink(message *tgbotapi.Message, args []string, paused bool) error {
+	if len(args) != 1 || !isURL(args[0]) {
+		if paused {
+			return c.sendText(message, msgPauseUsage)
+		}
+
+		return c.resumeAll(message, args)
+	}
+
+	err := c.Event.SetPaused(ownerID(message.Chat), args[0], paused)
+	if err == nil && !paused {
+		err = c.Event.SnoozeLink(ownerID(message.Chat), args[0], noSnooze)
+	}
+
+	if err != nil && !errors.Is(err, events.ErrLinkNotDB) && !errors.Is(err, events.ErrRefNotFound) {
+		return e.Wrap("set paused failed with an error: ", err)
+	}
+
+	msg := msgResumed
+
+	switch {
+	case err != nil:
+		msg = msgLinkNotTracked
+	case paused:
+		msg = msgPaused
+	}
+
+	return c.sendText(message, msg)
+}
+
+// resumeAll снимает общую отсрочку оповещений.
+func (c *Commands) resumeAll(message *tgbotapi.Message, args []string) error {
+	if len(args) != 0 {
+		return c.sendText(message, msgPauseUsage)
+	}
+
+	if err := c.Event.SnoozeAll(ownerID(message.Chat), noSnooze); err != nil {
+		return e.Wrap("snooze all failed with an error: ", err)
+	}
+
+	return c.sendText(message, msgResumedAll)
+}
+
+// snooze откладывает оповещения по ссылке или все сразу на заданное время.
+func (c *Commands) snooze(message *tgbotapi.Message, args []string) error {
+	if len(args) == 0 || len(args) > 2 || (len(args) == 2 && !isURL(args[1])) {
+		return c.sendText(message, msgSnoozeUsage)
+	}
+
+	d, ok := parseSnooze(args[0])
+	if !ok {
+		return c.sendText(message, msgSnoozeUsage)
+	}
+
+	timezone, err := c.userTimezone(ownerID(message.Chat))
+	if err != nil {
+		return e.Wrap("get user timezone failed with an error: ", err)
+	}
+
+	until := time.Now().Add(d).UTC()
+
+	if len(args) == 1 {
+		if err := c.addNewUser(message); err != nil {
+			return e.Wrap("save new user failed with an error: ", err)
+		}
+
+		if err := c.Event.SnoozeAll(ownerID(message.Chat), until); err != nil {
+			return e.Wrap("snooze all failed with an error: ", err)
+		}
+
+		return c.sendText(message, msgSnoozedAll, tz.Format(until, timezone))
+	}
+
+	err = c.Event.SnoozeLink(ownerID(message.Chat), args[1], until)
+	if err != nil && !errors.Is(err, events.ErrLinkNotDB) && !errors.Is(err, events.ErrRefNotFound) {
+		return e.Wrap("snooze link failed with an error: ", err)
+	}
+
+	if err != nil {
+		return c.sendText(message, msgLinkNotTracked)
+	}
+
+	return c.sendText(message, msgSnoozedLink, tz.Format(until, timezone))
+}
+
+// userTimezone возвращает часовой пояс пользователя.
+func (c *Commands) userTimezone(userID int) (string, error) {
+	userRow, err := c.Event.GetUser(userID)
+	if err != nil {
+		return "", e.Wrap("get user failed with an error: ", err)
+	}
+
+	if userRow == nil {
+		return tz.Default, nil
+	}
+
+	return userRow.Timezone, nil
+}
+
+// maxSnooze — на сколько можно отложить оповещения. Больше — уже не
+// откладывание, а отписка; кроме того, большие числа переполняют
+// time.Duration.
+const maxSnooze = 365 * 24 * time.Hour
+
+// parseSnooze разбирает длительность вида 30m, 2h, 3d или 1w не длиннее
+// maxSnooze.
+func parseSnooze(text string) (time.Duration, bool) {
+	m := snoozeRegexp.FindStringSubmatch(text)
+	if m == nil {
+		return 0, false
+	}
+
+	n, err := strconv.Atoi(m[1])
+	if err != nil || n <= 0 {
+		return 0, false
+	}
+
+	unit := map[string]time.Duration{
+		"m": time.Minute,
+		"h": time.Hour,
+		"d": 24 * time.Hour,
+		"w": 7 * 24 * time.Hour,
+	}[m[2]]
+
+	if n > int(maxSnooze/unit) {
+		return 0, false
+	}
+
+	return time.Duration(n) * unit, true
+}
@@ -0,0 +1,35 @@
+package commands
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_parseSnooze(t *testing.T) {
+	tests := []struct {
+		text   string
+		want   time.Duration
+		wantOK bool
+	}{
+		{text: "30m", want: 30 * time.Minute, wantOK: true},
+		{text: "2h", want: 2 * time.Hour, wantOK: true},
+		{text: "3d", want: 3 * 24 * time.Hour, wantOK: true},
+		{text: "1w", want: 7 * 24 * time.Hour, wantOK: true},
+		{text: "365d", want: maxSnooze, wantOK: true},
+		{text: "366d", wantOK: false},
+		{text: "53w", wantOK: false},
+		{text: "20000w", wantOK: false},
+		{text: "99999999999999999999m", wantOK: false},
+		{text: "0h", wantOK: false},
+		{text: "soon", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got, ok := parseSnooze(tt.text)
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("parseSnooze(%q) = %v, %v, want %v, %v", tt.text, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
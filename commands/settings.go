@@ -0,0 +1,55 @@
+package commands
+
+import "sync"
+
+// DefaultBroadcastRate — сообщений рассылки в секунду, с запасом ниже
+// ограничения Telegram в 30 сообщений в секунду.
+const DefaultBroadcastRate = 25
+
+// Settings — настройки команд, которые можно менять без перезапуска бота.
+type Settings struct {
+	// AdminIDs — пользователи Telegram, которым доступны команды
+	// администратора бота.
+	AdminIDs []int
+	// BroadcastRate — сколько сообщений рассылки отправлять в секунду.
+	BroadcastRate int
+}
+
+// settingsStore хранит настройки, общие для всех копий Commands.
+type settingsStore struct {
+	mu       sync.RWMutex
+	settings Settings
+}
+
+func newSettingsStore(s Settings) *settingsStore {
+	store := &settingsStore{}
+	store.set(s)
+
+	return store
+}
+
+func (s *settingsStore) get() Settings {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.settings
+}
+
+func (s *settingsStore) set(settings Settings) {
+	if settings.BroadcastRate <= 0 {
+		settings.BroadcastRate = DefaultBroadcastRate
+	}
+
+	settings.AdminIDs = append([]int(nil), settings.AdminIDs...)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.settings = settings
+}
+
+// SetSettings заменяет настройки команд. Рассылка, которая уже идет,
+// продолжается с прежней скоростью.
+func (c *Commands) SetSettings(s Settings) {
+	c.settings.set(s)
+}
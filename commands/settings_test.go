@@ -0,0 +1,42 @@
+package commands
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+func Test_SetSettings(t *testing.T) {
+	c := NewBotCommands(nil, nil, nil, Settings{AdminIDs: []int{1}}, slog.Default(), context.Background())
+	// Копия, как у WithLogger: настройки общие с исходными Commands.
+	scoped := *c
+
+	if got := c.settings.get().BroadcastRate; got != DefaultBroadcastRate {
+		t.Errorf("BroadcastRate = %d, want default %d", got, DefaultBroadcastRate)
+	}
+
+	ids := []int{2}
+	c.SetSettings(Settings{AdminIDs: ids, BroadcastRate: 10})
+	ids[0] = 3
+
+	tests := []struct {
+		id   int
+		want bool
+	}{
+		{id: 1, want: false},
+		{id: 2, want: true},
+		{id: 3, want: false},
+	}
+
+	for _, tt := range tests {
+		if got := scoped.isBotAdmin(&tgbotapi.User{ID: tt.id}); got != tt.want {
+			t.Errorf("isBotAdmin(%d) = %v, want %v", tt.id, got, tt.want)
+		}
+	}
+
+	if got := scoped.settings.get().BroadcastRate; got != 10 {
+		t.Errorf("BroadcastRate = %d, want 10", got)
+	}
+}
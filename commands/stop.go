@@ -0,0 +1,61 @@
+package commands
+
+import (
+	"github.com/EfimoffN/authorBot/lib/e"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+// stopPrefix отмечает callback-данные кнопок подтверждения /stop:
+// "stop:yes" или "stop:no".
+const stopPrefix = "stop"
+
+const (
+	stopYes = "yes"
+	stopNo  = "no"
+)
+
+// askStop просит подтвердить удаление пользователя и его подписок.
+func (c *Commands) askStop(message *tgbotapi.Message) error {
+	l := c.locale(message.Chat, message.From)
+
+	m := tgbotapi.NewMessage(message.Chat.ID, l.T(msgStopAsk))
+	m.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(l.T(msgStopYes), stopPrefix+":"+stopYes),
+			tgbotapi.NewInlineKeyboardButtonData(l.T(msgStopNo), stopPrefix+":"+stopNo),
+		),
+	)
+
+	if _, err := c.BotAPI.Send(m); err != nil {
+		return e.Wrap("Sending the message failed with an error: ", err)
+	}
+
+	return nil
+}
+
+// doStopCallback удаляет пользователя после подтверждения и убирает
+// кнопки из сообщения с вопросом.
+func (c *Commands) doStopCallback(query *tgbotapi.CallbackQuery, answer string) error {
+	// Язык определяется до удаления, пока выбранный пользователем язык еще
+	// сохранен.
+	l := c.locale(query.Message.Chat, query.From)
+	msg := msgStopCancelled
+
+	switch answer {
+	case stopYes:
+		if err := c.Event.RemoveUser(ownerID(query.Message.Chat)); err != nil {
+			return e.Wrap("remove user failed with an error: ", err)
+		}
+
+		msg = msgStopDone
+	case stopNo:
+	default:
+		return c.answerCallback(query, "")
+	}
+
+	if err := c.answerCallback(query, ""); err != nil {
+		return err
+	}
+
+	return c.editText(query.Message, l.T(msg), nil)
+}
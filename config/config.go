@@ -1,48 +1,324 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"net"
+	"net/url"
 	"os"
+	"reflect"
+	"strconv"
+	"strings"
 
 	"github.com/EfimoffN/authorBot/lib/e"
+	"github.com/EfimoffN/authorBot/lib/logger"
+	"github.com/EfimoffN/authorBot/sources"
 	"gopkg.in/yaml.v3"
 )
 
+// EnvPrefix — префикс переменных окружения, которые заменяют поля файла
+// конфигурации: AUTHORBOT_ и имя поля в верхнем регистре, например
+// AUTHORBOT_CHECK_INTERVAL=15. Списки задаются через запятую.
+const EnvPrefix = "AUTHORBOT_"
+
+// Значения по умолчанию.
+const (
+	DefaultLogLevel      = "info"
+	DefaultLogFormat     = logger.FormatText
+	DefaultTimeout       = 60
+	DefaultCheckInterval = 30
+	DefaultBroadcastRate = 25
+)
+
+// maxBroadcastRate — ограничение Telegram на сообщения в секунду.
+const maxBroadcastRate = 30
+
 type ConfigApp struct {
-	BotToken        string `yaml: "bot_token"`
-	BindAddr        string `yaml: "bind_addr"`
-	LogLevel        string `yaml: "log_level"`
-	ConnectPostgres string `yaml: "connect_postgres"`
-	Timeout         int    `yaml: "timeout"`
+	BotToken        string   `yaml:"bot_token"`
+	BindAddr        string   `yaml:"bind_addr"`
+	LogLevel        string   `yaml:"log_level"`
+	LogFormat       string   `yaml:"log_format"`
+	ConnectPostgres string   `yaml:"connect_postgres"`
+	Timeout         int      `yaml:"timeout"`
+	CheckInterval   int      `yaml:"check_interval"`
+	APIURL          string   `yaml:"api_url"`
+	APIToken        string   `yaml:"api_token"`
+	APIKinds        []string `yaml:"api_kinds"`
+	TemplatesDir    string   `yaml:"templates_dir"`
+	AdminIDs        []int    `yaml:"admin_ids"`
+	BroadcastRate   int      `yaml:"broadcast_rate"`
+}
+
+// Default возвращает конфигурацию со значениями по умолчанию.
+func Default() *ConfigApp {
+	return &ConfigApp{
+		LogLevel:      DefaultLogLevel,
+		LogFormat:     DefaultLogFormat,
+		Timeout:       DefaultTimeout,
+		CheckInterval: DefaultCheckInterval,
+		BroadcastRate: DefaultBroadcastRate,
+	}
 }
 
-func CreateConfig(configPath string) (*ConfigApp, error) {
-	err := validateConfigAppPath(configPath)
+// Load собирает конфигурацию и проверяет ее.
+func Load(configPath string) (*ConfigApp, error) {
+	config, err := Read(configPath)
 	if err != nil {
-		return nil, e.Wrap("validat config path", err)
+		return nil, err
 	}
 
-	config := &ConfigApp{}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// Read собирает конфигурацию без проверки: значения по умолчанию, файл
+// configPath (если задан), переменные AUTHORBOT_* и секреты из окружения.
+func Read(configPath string) (*ConfigApp, error) {
+	return read(configPath, os.Getenv)
+}
 
+func read(configPath string, getenv func(string) string) (*ConfigApp, error) {
+	config := Default()
+
+	if configPath != "" {
+		if err := readFile(configPath, config); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := applyEnv(config, getenv); err != nil {
+		return nil, e.Wrap("environment", err)
+	}
+
+	if err := loadSecrets(config, getenv); err != nil {
+		return nil, e.Wrap("load secrets", err)
+	}
+
+	return config, nil
+}
+
+func readFile(configPath string, config *ConfigApp) error {
 	file, err := os.Open(configPath)
 	if err != nil {
-		return nil, e.Wrap("open file", err)
+		return e.Wrap(fmt.Sprintf("config path: '%s'", configPath), err)
 	}
 	defer file.Close()
 
 	d := yaml.NewDecoder(file)
+	d.KnownFields(true)
 
-	if err := d.Decode(&config); err != nil {
-		return nil, e.Wrap("decoder file", err)
+	if err := d.Decode(config); err != nil && !errors.Is(err, io.EOF) {
+		return e.Wrap("decoder file", err)
 	}
 
-	return config, nil
+	return nil
 }
 
-func validateConfigAppPath(path string) error {
-	_, err := os.Stat(path)
-	if err != nil {
-		return e.Wrap(fmt.Sprintf("config path: '%s'", path), err)
+// applyEnv заменяет поля конфигурации значениями переменных AUTHORBOT_*.
+// Ошибки разбора собираются по всем переменным.
+func applyEnv(config *ConfigApp, getenv func(string) string) error {
+	var errs []error
+
+	v := reflect.ValueOf(config).Elem()
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		name := EnvPrefix + strings.ToUpper(t.Field(i).Tag.Get("yaml"))
+
+		value := getenv(name)
+		if value == "" {
+			continue
+		}
+
+		if err := setField(v.Field(i), value); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func setField(field reflect.Value, value string) error {
+	switch field.Interface().(type) {
+	case string:
+		field.SetString(value)
+	case int:
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return fmt.Errorf("%q is not a number", value)
+		}
+
+		field.SetInt(int64(n))
+	case []string:
+		field.Set(reflect.ValueOf(splitList(value)))
+	case []int:
+		ids := []int{}
+
+		for _, s := range splitList(value) {
+			n, err := strconv.Atoi(s)
+			if err != nil {
+				return fmt.Errorf("%q is not a number", s)
+			}
+
+			ids = append(ids, n)
+		}
+
+		field.Set(reflect.ValueOf(ids))
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Type())
+	}
+
+	return nil
+}
+
+// splitList разбирает список через запятую, пропуская пустые элементы.
+func splitList(value string) []string {
+	list := []string{}
+
+	for _, s := range strings.Split(value, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			list = append(list, s)
+		}
+	}
+
+	return list
+}
+
+// Validate проверяет конфигурацию и возвращает все найденные ошибки разом.
+func (cfg *ConfigApp) Validate() error {
+	var errs []error
+
+	add := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if cfg.BotToken == "" {
+		add("bot_token is required (set it in the config, %s or %s%s)", EnvBotToken, EnvBotToken, fileSuffix)
+	}
+
+	if cfg.ConnectPostgres == "" {
+		add("connect_postgres is required (set it in the config, %s or %s%s)", EnvPgDSN, EnvPgDSN, fileSuffix)
+	}
+
+	if cfg.BindAddr != "" {
+		if _, _, err := net.SplitHostPort(cfg.BindAddr); err != nil {
+			add("bind_addr %q is not a host:port address", cfg.BindAddr)
+		}
+	}
+
+	if _, err := logger.ParseLevel(cfg.LogLevel); err != nil {
+		add("log_level %q must be one of debug, info, warn, error", cfg.LogLevel)
+	}
+
+	if f := strings.ToLower(cfg.LogFormat); f != logger.FormatText && f != logger.FormatJSON {
+		add("log_format %q must be %s or %s", cfg.LogFormat, logger.FormatText, logger.FormatJSON)
+	}
+
+	if cfg.Timeout <= 0 {
+		add("timeout must be positive, got %d", cfg.Timeout)
+	}
+
+	if cfg.CheckInterval <= 0 {
+		add("check_interval must be positive, got %d", cfg.CheckInterval)
+	}
+
+	if cfg.BroadcastRate <= 0 || cfg.BroadcastRate > maxBroadcastRate {
+		add("broadcast_rate must be between 1 and %d, got %d", maxBroadcastRate, cfg.BroadcastRate)
+	}
+
+	if cfg.APIURL != "" {
+		if u, err := url.Parse(cfg.APIURL); err != nil || u.Scheme == "" || u.Host == "" {
+			add("api_url %q is not an absolute URL", cfg.APIURL)
+		}
+	}
+
+	for _, k := range cfg.APIKinds {
+		if k != sources.KindBook && k != sources.KindAuthor && k != sources.KindSeries {
+			add("api_kinds: unknown kind %q, want %s, %s or %s", k, sources.KindBook, sources.KindAuthor, sources.KindSeries)
+		}
+	}
+
+	if cfg.TemplatesDir != "" {
+		if info, err := os.Stat(cfg.TemplatesDir); err != nil || !info.IsDir() {
+			add("templates_dir %q is not a directory", cfg.TemplatesDir)
+		}
+	}
+
+	for _, id := range cfg.AdminIDs {
+		if id <= 0 {
+			add("admin_ids: %d is not a Telegram user ID", id)
+		}
+	}
+
+	if len(errs) > 0 {
+		return &ValidationError{Errs: errs}
+	}
+
+	return nil
+}
+
+// reloadable — поля, которые применяются по SIGHUP без перезапуска бота.
+var reloadable = map[string]bool{
+	"log_level":      true,
+	"check_interval": true,
+	"broadcast_rate": true,
+	"admin_ids":      true,
+	"templates_dir":  true,
+}
+
+// RestartRequired возвращает имена измененных полей, которые вступят в
+// силу только после перезапуска бота.
+func RestartRequired(old, new *ConfigApp) []string {
+	fields := []string{}
+
+	ov, nv := reflect.ValueOf(old).Elem(), reflect.ValueOf(new).Elem()
+	t := ov.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		name := t.Field(i).Tag.Get("yaml")
+		if reloadable[name] {
+			continue
+		}
+
+		if !reflect.DeepEqual(ov.Field(i).Interface(), nv.Field(i).Interface()) {
+			fields = append(fields, name)
+		}
+	}
+
+	return fields
+}
+
+// ValidationError — все ошибки конфигурации, по одной на строку.
+type ValidationError struct {
+	Errs []error
+}
+
+func (v *ValidationError) Error() string {
+	lines := make([]string, 0, len(v.Errs)+1)
+	lines = append(lines, "invalid configuration:")
+
+	for _, err := range v.Errs {
+		lines = append(lines, "  - "+err.Error())
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func (v *ValidationError) Unwrap() []error {
+	return v.Errs
+}
+
+// Print выводит конфигурацию в формате YAML со скрытыми секретами.
+func (cfg *ConfigApp) Print(w io.Writer) error {
+	enc := yaml.NewEncoder(w)
+	defer enc.Close()
+
+	if err := enc.Encode(cfg.Redacted()); err != nil {
+		return e.Wrap("encode config", err)
 	}
 
 	return nil
@@ -0,0 +1,231 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func Test_read(t *testing.T) {
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "config.yml")
+	data := "bot_token: yaml-token\nconnect_postgres: yaml-dsn\ncheck_interval: 10\napi_kinds: [book]\n"
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	unknown := filepath.Join(dir, "unknown.yml")
+	if err := os.WriteFile(unknown, []byte("bottoken: yaml-token\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		env     map[string]string
+		want    *ConfigApp
+		wantErr bool
+	}{
+		{
+			name: "defaults only",
+			env:  map[string]string{},
+			want: Default(),
+		},
+		{
+			name: "file over defaults",
+			path: path,
+			env:  map[string]string{},
+			want: &ConfigApp{
+				BotToken:        "yaml-token",
+				ConnectPostgres: "yaml-dsn",
+				LogLevel:        DefaultLogLevel,
+				LogFormat:       DefaultLogFormat,
+				Timeout:         DefaultTimeout,
+				CheckInterval:   10,
+				APIKinds:        []string{"book"},
+				BroadcastRate:   DefaultBroadcastRate,
+			},
+		},
+		{
+			name: "env over file",
+			path: path,
+			env: map[string]string{
+				"AUTHORBOT_CHECK_INTERVAL": "15",
+				"AUTHORBOT_API_KINDS":      "book, series,",
+				"AUTHORBOT_ADMIN_IDS":      "1,2",
+				"AUTHORBOT_LOG_LEVEL":      "debug",
+				EnvBotToken:                "env-token",
+			},
+			want: &ConfigApp{
+				BotToken:        "env-token",
+				ConnectPostgres: "yaml-dsn",
+				LogLevel:        "debug",
+				LogFormat:       DefaultLogFormat,
+				Timeout:         DefaultTimeout,
+				CheckInterval:   15,
+				APIKinds:        []string{"book", "series"},
+				AdminIDs:        []int{1, 2},
+				BroadcastRate:   DefaultBroadcastRate,
+			},
+		},
+		{
+			name:    "bad env number",
+			env:     map[string]string{"AUTHORBOT_TIMEOUT": "soon"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown field in file",
+			path:    unknown,
+			env:     map[string]string{},
+			wantErr: true,
+		},
+		{
+			name:    "missing file",
+			path:    filepath.Join(dir, "missing.yml"),
+			env:     map[string]string{},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := read(tt.path, func(key string) string { return tt.env[key] })
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("read() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if err == nil && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("read() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_Validate(t *testing.T) {
+	valid := func() *ConfigApp {
+		cfg := Default()
+		cfg.BotToken = "token"
+		cfg.ConnectPostgres = "postgres://localhost/botdb"
+
+		return cfg
+	}
+
+	tests := []struct {
+		name   string
+		modify func(cfg *ConfigApp)
+		want   []string
+	}{
+		{
+			name:   "valid",
+			modify: func(cfg *ConfigApp) {},
+		},
+		{
+			name: "required secrets",
+			modify: func(cfg *ConfigApp) {
+				cfg.BotToken = ""
+				cfg.ConnectPostgres = ""
+			},
+			want: []string{"bot_token is required", "connect_postgres is required"},
+		},
+		{
+			name: "all problems reported",
+			modify: func(cfg *ConfigApp) {
+				cfg.Timeout = 0
+				cfg.CheckInterval = -1
+				cfg.LogLevel = "verbose"
+				cfg.LogFormat = "xml"
+				cfg.BindAddr = "8080"
+				cfg.APIURL = "api.author.today"
+				cfg.APIKinds = []string{"chapter"}
+				cfg.AdminIDs = []int{0}
+				cfg.BroadcastRate = 31
+			},
+			want: []string{"timeout", "check_interval", "log_level", "log_format", "bind_addr", "api_url", "api_kinds", "admin_ids", "broadcast_rate"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := valid()
+			tt.modify(cfg)
+
+			err := cfg.Validate()
+			if len(tt.want) == 0 {
+				if err != nil {
+					t.Fatalf("Validate() error = %v", err)
+				}
+
+				return
+			}
+
+			var verr *ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("Validate() error = %v, want ValidationError", err)
+			}
+
+			if len(verr.Errs) != len(tt.want) {
+				t.Errorf("Validate() found %d problems, want %d:\n%v", len(verr.Errs), len(tt.want), err)
+			}
+
+			for _, w := range tt.want {
+				if !strings.Contains(err.Error(), w) {
+					t.Errorf("Validate() error does not mention %q:\n%v", w, err)
+				}
+			}
+		})
+	}
+}
+
+func Test_RestartRequired(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(cfg *ConfigApp)
+		want   []string
+	}{
+		{
+			name:   "nothing changed",
+			modify: func(cfg *ConfigApp) {},
+			want:   []string{},
+		},
+		{
+			name: "reloadable only",
+			modify: func(cfg *ConfigApp) {
+				cfg.LogLevel = "debug"
+				cfg.CheckInterval = 5
+				cfg.BroadcastRate = 10
+				cfg.AdminIDs = []int{2}
+				cfg.TemplatesDir = "/etc/authorbot/templates"
+			},
+			want: []string{},
+		},
+		{
+			name: "token and DSN",
+			modify: func(cfg *ConfigApp) {
+				cfg.BotToken = "new-token"
+				cfg.ConnectPostgres = "new-dsn"
+				cfg.AdminIDs = nil
+			},
+			want: []string{"bot_token", "connect_postgres"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			old := Default()
+			old.BotToken = "token"
+			old.ConnectPostgres = "dsn"
+			old.AdminIDs = []int{1}
+
+			new := *old
+			tt.modify(&new)
+
+			if got := RestartRequired(old, &new); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("RestartRequired() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
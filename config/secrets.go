@@ -0,0 +1,95 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/EfimoffN/authorBot/lib/e"
+	"github.com/EfimoffN/authorBot/lib/redact"
+)
+
+// Переменные окружения с секретами. Секрет задается либо самой переменной,
+// либо переменной с суффиксом _FILE — путем к файлу с секретом, например
+// BOT_TOKEN_FILE=/run/secrets/bot_token. Секрет из окружения заменяет
+// значение из файла конфигурации.
+const (
+	EnvBotToken = "BOT_TOKEN"
+	EnvPgDSN    = "PG_DSN"
+	EnvAPIToken = "API_TOKEN"
+
+	fileSuffix = "_FILE"
+)
+
+// loadSecrets читает секреты из окружения и файлов.
+func loadSecrets(cfg *ConfigApp, getenv func(string) string) error {
+	secrets := []struct {
+		env   string
+		value *string
+	}{
+		{env: EnvBotToken, value: &cfg.BotToken},
+		{env: EnvPgDSN, value: &cfg.ConnectPostgres},
+		{env: EnvAPIToken, value: &cfg.APIToken},
+	}
+
+	for _, s := range secrets {
+		value, err := secret(s.env, getenv)
+		if err != nil {
+			return err
+		}
+
+		if value != "" {
+			*s.value = value
+		}
+	}
+
+	return nil
+}
+
+// secret возвращает значение переменной env или содержимое файла из
+// переменной env_FILE без завершающих пробелов и переводов строки.
+func secret(env string, getenv func(string) string) (string, error) {
+	value, path := getenv(env), getenv(env+fileSuffix)
+
+	switch {
+	case value != "" && path != "":
+		return "", fmt.Errorf("both %s and %s%s are set", env, env, fileSuffix)
+	case path == "":
+		return value, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", e.Wrap(fmt.Sprintf("read %s%s", env, fileSuffix), err)
+	}
+
+	return strings.TrimSpace(string(data)), nil
+}
+
+// Secrets возвращает секреты конфигурации, которые нельзя выводить в журнал.
+func (cfg *ConfigApp) Secrets() []string {
+	secrets := []string{cfg.BotToken, cfg.ConnectPostgres, cfg.APIToken}
+
+	if u, err := url.Parse(cfg.ConnectPostgres); err == nil && u.User != nil {
+		if password, ok := u.User.Password(); ok {
+			secrets = append(secrets, password)
+		}
+	}
+
+	return secrets
+}
+
+// Redacted возвращает копию конфигурации со скрытыми секретами: токены
+// заменены целиком, в строке подключения к базе скрыт только пароль.
+func (cfg *ConfigApp) Redacted() *ConfigApp {
+	c := *cfg
+
+	c.BotToken = redact.Secret(cfg.BotToken)
+	c.APIToken = redact.Secret(cfg.APIToken)
+	c.ConnectPostgres = redact.DSN(cfg.ConnectPostgres)
+	c.AdminIDs = append([]int(nil), cfg.AdminIDs...)
+	c.APIKinds = append([]string(nil), cfg.APIKinds...)
+
+	return &c
+}
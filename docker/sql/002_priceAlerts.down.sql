@@ -0,0 +1,10 @@
+DROP TABLE link_price;
+
+ALTER TABLE ref_link_user
+    DROP COLUMN pricealert,
+    DROP COLUMN pricebelow;
+
+ALTER TABLE prj_link
+    DROP COLUMN title,
+    DROP COLUMN chapters,
+    DROP COLUMN checked;
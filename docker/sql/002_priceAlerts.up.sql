@@ -0,0 +1,20 @@
+ALTER TABLE prj_link
+    ADD COLUMN title CHARACTER VARYING(300) NOT NULL DEFAULT '',
+    ADD COLUMN chapters INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN checked BOOLEAN NOT NULL DEFAULT false;
+
+ALTER TABLE ref_link_user
+    ADD COLUMN pricealert BOOLEAN NOT NULL DEFAULT false,
+    ADD COLUMN pricebelow INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE link_price(
+    priceid CHARACTER VARYING(32) NOT NULL UNIQUE,
+    linkid CHARACTER VARYING(32) NOT NULL,
+    price INTEGER NOT NULL,
+    discount INTEGER NOT NULL,
+    free BOOLEAN NOT NULL,
+    created TIMESTAMP NOT NULL DEFAULT now(),
+
+    CONSTRAINT pk_link_price PRIMARY KEY (priceid),
+    FOREIGN KEY (linkid) REFERENCES prj_link (linkid) ON DELETE CASCADE
+);
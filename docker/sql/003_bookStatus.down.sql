@@ -0,0 +1,6 @@
+ALTER TABLE ref_link_user
+    DROP COLUMN chapteralert,
+    DROP COLUMN statusalert;
+
+ALTER TABLE prj_link
+    DROP COLUMN status;
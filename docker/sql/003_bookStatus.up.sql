@@ -0,0 +1,6 @@
+ALTER TABLE prj_link
+    ADD COLUMN status CHARACTER VARYING(32) NOT NULL DEFAULT '';
+
+ALTER TABLE ref_link_user
+    ADD COLUMN chapteralert BOOLEAN NOT NULL DEFAULT true,
+    ADD COLUMN statusalert BOOLEAN NOT NULL DEFAULT true;
@@ -0,0 +1,4 @@
+DROP TABLE link_item;
+
+ALTER TABLE prj_link
+    DROP COLUMN kind;
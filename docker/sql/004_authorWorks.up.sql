@@ -0,0 +1,14 @@
+ALTER TABLE prj_link
+    ADD COLUMN kind CHARACTER VARYING(16) NOT NULL DEFAULT 'book';
+
+CREATE TABLE link_item(
+    itemid CHARACTER VARYING(32) NOT NULL UNIQUE,
+    linkid CHARACTER VARYING(32) NOT NULL,
+    kind CHARACTER VARYING(16) NOT NULL,
+    externalid CHARACTER VARYING(32) NOT NULL,
+    title CHARACTER VARYING(300) NOT NULL,
+
+    CONSTRAINT pk_link_item PRIMARY KEY (itemid),
+    CONSTRAINT uq_link_item UNIQUE (linkid, kind, externalid),
+    FOREIGN KEY (linkid) REFERENCES prj_link (linkid) ON DELETE CASCADE
+);
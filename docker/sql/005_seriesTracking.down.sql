@@ -0,0 +1,6 @@
+ALTER TABLE link_item
+    DROP COLUMN position,
+    DROP COLUMN status;
+
+ALTER TABLE prj_link
+    DROP COLUMN volumes;
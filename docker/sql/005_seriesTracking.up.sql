@@ -0,0 +1,6 @@
+ALTER TABLE prj_link
+    ADD COLUMN volumes INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE link_item
+    ADD COLUMN position INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN status CHARACTER VARYING(32) NOT NULL DEFAULT '';
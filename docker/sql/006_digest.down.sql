@@ -0,0 +1,6 @@
+DROP TABLE pending_notice;
+
+ALTER TABLE prj_user
+    DROP COLUMN delivery,
+    DROP COLUMN digesthour,
+    DROP COLUMN lastdigest;
@@ -0,0 +1,17 @@
+ALTER TABLE prj_user
+    ADD COLUMN delivery CHARACTER VARYING(16) NOT NULL DEFAULT 'instant',
+    ADD COLUMN digesthour INTEGER NOT NULL DEFAULT 9,
+    ADD COLUMN lastdigest TIMESTAMP NOT NULL DEFAULT now();
+
+CREATE TABLE pending_notice(
+    noticeid CHARACTER VARYING(32) NOT NULL UNIQUE,
+    userid CHARACTER VARYING(32) NOT NULL,
+    chatid CHARACTER VARYING(32) NOT NULL,
+    linkid CHARACTER VARYING(32) NOT NULL,
+    text TEXT NOT NULL,
+    created TIMESTAMP NOT NULL DEFAULT now(),
+
+    CONSTRAINT pk_pending_notice PRIMARY KEY (noticeid),
+    FOREIGN KEY (userid) REFERENCES prj_user (userid) ON DELETE CASCADE,
+    FOREIGN KEY (linkid) REFERENCES prj_link (linkid) ON DELETE CASCADE
+);
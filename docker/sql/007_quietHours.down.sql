@@ -0,0 +1,7 @@
+ALTER TABLE prj_link
+    DROP COLUMN lastcheck;
+
+ALTER TABLE prj_user
+    DROP COLUMN timezone,
+    DROP COLUMN quietstart,
+    DROP COLUMN quietend;
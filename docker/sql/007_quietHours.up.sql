@@ -0,0 +1,7 @@
+ALTER TABLE prj_user
+    ADD COLUMN timezone CHARACTER VARYING(64) NOT NULL DEFAULT 'Europe/Moscow',
+    ADD COLUMN quietstart INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN quietend INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE prj_link
+    ADD COLUMN lastcheck TIMESTAMP NOT NULL DEFAULT now();
@@ -0,0 +1,12 @@
+ALTER TABLE link_item
+    DROP COLUMN comments;
+
+ALTER TABLE prj_link
+    DROP COLUMN annotation;
+
+ALTER TABLE ref_link_user
+    DROP COLUMN blogalert,
+    DROP COLUMN commentalert,
+    DROP COLUMN annotationalert,
+    DROP COLUMN include,
+    DROP COLUMN exclude;
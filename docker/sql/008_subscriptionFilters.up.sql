@@ -0,0 +1,12 @@
+ALTER TABLE ref_link_user
+    ADD COLUMN blogalert BOOLEAN NOT NULL DEFAULT true,
+    ADD COLUMN commentalert BOOLEAN NOT NULL DEFAULT true,
+    ADD COLUMN annotationalert BOOLEAN NOT NULL DEFAULT true,
+    ADD COLUMN include TEXT NOT NULL DEFAULT '',
+    ADD COLUMN exclude TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE prj_link
+    ADD COLUMN annotation TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE link_item
+    ADD COLUMN comments INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,6 @@
+ALTER TABLE prj_user
+    DROP COLUMN snoozeuntil;
+
+ALTER TABLE ref_link_user
+    DROP COLUMN paused,
+    DROP COLUMN snoozeuntil;
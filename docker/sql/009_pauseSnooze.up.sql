@@ -0,0 +1,6 @@
+ALTER TABLE ref_link_user
+    ADD COLUMN paused BOOLEAN NOT NULL DEFAULT false,
+    ADD COLUMN snoozeuntil TIMESTAMP NOT NULL DEFAULT '1970-01-01 00:00:00';
+
+ALTER TABLE prj_user
+    ADD COLUMN snoozeuntil TIMESTAMP NOT NULL DEFAULT '1970-01-01 00:00:00';
@@ -0,0 +1,2 @@
+ALTER TABLE ref_link_user
+    DROP COLUMN created;
@@ -0,0 +1,2 @@
+ALTER TABLE ref_link_user
+    ADD COLUMN created TIMESTAMP NOT NULL DEFAULT now();
@@ -0,0 +1,10 @@
+ALTER TABLE pending_notice
+    DROP CONSTRAINT pending_notice_userid_fkey,
+    ADD CONSTRAINT pending_notice_userid_fkey FOREIGN KEY (userid) REFERENCES prj_user (userid) ON DELETE CASCADE;
+
+ALTER TABLE ref_link_user
+    DROP CONSTRAINT ref_link_user_userid_fkey,
+    ADD CONSTRAINT ref_link_user_userid_fkey FOREIGN KEY (userid) REFERENCES prj_user (userid) ON DELETE CASCADE;
+
+ALTER TABLE prj_user
+    ADD CONSTRAINT prj_user_chatid_key UNIQUE (chatid);
@@ -0,0 +1,10 @@
+ALTER TABLE prj_user
+    DROP CONSTRAINT prj_user_chatid_key;
+
+ALTER TABLE ref_link_user
+    DROP CONSTRAINT ref_link_user_userid_fkey,
+    ADD CONSTRAINT ref_link_user_userid_fkey FOREIGN KEY (userid) REFERENCES prj_user (userid) ON DELETE CASCADE ON UPDATE CASCADE;
+
+ALTER TABLE pending_notice
+    DROP CONSTRAINT pending_notice_userid_fkey,
+    ADD CONSTRAINT pending_notice_userid_fkey FOREIGN KEY (userid) REFERENCES prj_user (userid) ON DELETE CASCADE ON UPDATE CASCADE;
@@ -0,0 +1,2 @@
+ALTER TABLE prj_user
+    ADD COLUMN lang CHARACTER VARYING(8) NOT NULL DEFAULT '';
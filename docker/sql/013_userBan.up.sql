@@ -0,0 +1,2 @@
+ALTER TABLE prj_user
+    ADD COLUMN banned BOOLEAN NOT NULL DEFAULT false;
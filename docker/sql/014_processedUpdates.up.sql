@@ -0,0 +1,6 @@
+CREATE TABLE processed_update(
+    updateid BIGINT NOT NULL,
+    processed TIMESTAMP NOT NULL DEFAULT now(),
+
+    CONSTRAINT pk_processed_update PRIMARY KEY (updateid)
+);
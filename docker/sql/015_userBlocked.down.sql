@@ -0,0 +1,2 @@
+ALTER TABLE prj_user
+    DROP COLUMN blocked;
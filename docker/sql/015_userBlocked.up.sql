@@ -0,0 +1,2 @@
+ALTER TABLE prj_user
+    ADD COLUMN blocked BOOLEAN NOT NULL DEFAULT false;
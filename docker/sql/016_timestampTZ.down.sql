@@ -0,0 +1,18 @@
+ALTER TABLE link_price
+    ALTER COLUMN created TYPE TIMESTAMP USING created AT TIME ZONE current_setting('TimeZone');
+
+ALTER TABLE ref_link_user
+    ALTER COLUMN created TYPE TIMESTAMP USING created AT TIME ZONE current_setting('TimeZone'),
+    ALTER COLUMN snoozeuntil TYPE TIMESTAMP USING snoozeuntil AT TIME ZONE current_setting('TimeZone'),
+    ALTER COLUMN snoozeuntil SET DEFAULT '1970-01-01 00:00:00';
+
+ALTER TABLE prj_link
+    ALTER COLUMN lastcheck TYPE TIMESTAMP USING lastcheck AT TIME ZONE current_setting('TimeZone');
+
+ALTER TABLE pending_notice
+    ALTER COLUMN created TYPE TIMESTAMP USING created AT TIME ZONE current_setting('TimeZone');
+
+ALTER TABLE prj_user
+    ALTER COLUMN lastdigest TYPE TIMESTAMP USING lastdigest AT TIME ZONE current_setting('TimeZone'),
+    ALTER COLUMN snoozeuntil TYPE TIMESTAMP USING snoozeuntil AT TIME ZONE current_setting('TimeZone'),
+    ALTER COLUMN snoozeuntil SET DEFAULT '1970-01-01 00:00:00';
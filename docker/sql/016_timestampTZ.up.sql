@@ -0,0 +1,18 @@
+ALTER TABLE prj_user
+    ALTER COLUMN lastdigest TYPE TIMESTAMPTZ USING lastdigest AT TIME ZONE current_setting('TimeZone'),
+    ALTER COLUMN snoozeuntil TYPE TIMESTAMPTZ USING snoozeuntil AT TIME ZONE current_setting('TimeZone'),
+    ALTER COLUMN snoozeuntil SET DEFAULT '1970-01-01 00:00:00+00';
+
+ALTER TABLE pending_notice
+    ALTER COLUMN created TYPE TIMESTAMPTZ USING created AT TIME ZONE current_setting('TimeZone');
+
+ALTER TABLE prj_link
+    ALTER COLUMN lastcheck TYPE TIMESTAMPTZ USING lastcheck AT TIME ZONE current_setting('TimeZone');
+
+ALTER TABLE ref_link_user
+    ALTER COLUMN snoozeuntil TYPE TIMESTAMPTZ USING snoozeuntil AT TIME ZONE current_setting('TimeZone'),
+    ALTER COLUMN snoozeuntil SET DEFAULT '1970-01-01 00:00:00+00',
+    ALTER COLUMN created TYPE TIMESTAMPTZ USING created AT TIME ZONE current_setting('TimeZone');
+
+ALTER TABLE link_price
+    ALTER COLUMN created TYPE TIMESTAMPTZ USING created AT TIME ZONE current_setting('TimeZone');
@@ -0,0 +1,2 @@
+ALTER TABLE processed_update
+    ALTER COLUMN processed TYPE TIMESTAMPTZ USING processed AT TIME ZONE current_setting('TimeZone');
@@ -3,10 +3,15 @@ package events
 import (
 	"context"
 	"errors"
+	"log/slog"
 	"net/url"
 	"strings"
+	"time"
 
 	"github.com/EfimoffN/authorBot/lib/e"
+	"github.com/EfimoffN/authorBot/lib/logger"
+	"github.com/EfimoffN/authorBot/lib/tz"
+	"github.com/EfimoffN/authorBot/sources"
 	"github.com/EfimoffN/authorBot/sqlapi"
 
 	"github.com/google/uuid"
@@ -18,26 +23,97 @@ type IEvent interface {
 	RemoveRefUserLink(userID int, link string) error
 	GetAllUserLinks(userID int) ([]*sqlapi.LinkRow, error)
 	GetLinkByLink(link string) (*sqlapi.LinkRow, error)
+	SetPriceAlert(userID int, link string, enabled bool, below int) error
+	SetChapterAlert(userID int, link string, enabled bool) error
+	SetStatusAlert(userID int, link string, enabled bool) error
+	GetAllLinks() ([]*sqlapi.LinkRow, error)
+	UpdateLink(link sqlapi.LinkRow) error
+	GetSubscribers(linkID string) ([]*sqlapi.SubscriberRow, error)
+	GetLastPrice(linkID string) (*sqlapi.PriceRow, error)
+	AddPrice(linkID string, price, discount int, free bool) error
+	GetLinkItems(linkID string) ([]*sqlapi.ItemRow, error)
+	AddLinkItem(item sqlapi.ItemRow) error
+	UpdateLinkItem(item sqlapi.ItemRow) error
+	SetDelivery(userID int, delivery string, hour int) error
+	SetLastDigest(userID int, sent time.Time) error
+	AddNotice(userID int, chatID int64, linkID, text string) error
+	GetPendingUsers() ([]*sqlapi.UserRow, error)
+	GetNotices(userID int) ([]*sqlapi.NoticeRow, error)
+	RemoveNotices(userID int, before time.Time) error
+	GetUser(userID int) (*sqlapi.UserRow, error)
+	SetTimezone(userID int, timezone string) error
+	SetQuietHours(userID int, start, end int) error
+	GetSubscription(userID int, link string) (*sqlapi.RefRow, error)
+	GetSubscriptionByID(userID int, refID string) (*sqlapi.RefRow, error)
+	UpdateSubscription(ref sqlapi.RefRow) error
+	SetPaused(userID int, link string, paused bool) error
+	SnoozeLink(userID int, link string, until time.Time) error
+	SnoozeAll(userID int, until time.Time) error
+	RemoveUser(userID int) error
+	GetSubscriptions(userID int) ([]*sqlapi.RefRow, error)
+	ImportSubscription(userID int, ref sqlapi.RefRow) error
+	SetChatTarget(userID int, chatID int64) error
+	MigrateChat(fromChatID, toChatID int64) error
+	SetLanguage(userID int, lang string) error
+	SetBanned(userID int, banned bool) error
+	SetBlocked(userID int, blocked bool) error
+	JoinChat(name string, userID int, chatID int64) error
+	LeaveChannel(chatID int64) error
+	WithLogger(log *slog.Logger) IEvent
+	GetActiveUsers() ([]*sqlapi.UserRow, error)
+	GetStats() (*sqlapi.StatsRow, error)
+	LastUpdateID() (int, error)
+	ClaimUpdate(updateID int) (bool, error)
+	PruneUpdates() error
 }
 
+// Способы доставки оповещений.
+const (
+	DeliveryInstant = "instant"
+	DeliveryDaily   = "daily"
+	DeliveryWeekly  = "weekly"
+)
+
+// updateRetention — сколько хранятся отметки об обработанных обновлениях.
+// Telegram хранит неподтвержденные обновления не дольше суток, а через
+// неделю без обновлений может начать их нумерацию заново, поэтому более
+// старая отметка не годится для продолжения работы.
+const updateRetention = 7 * 24 * time.Hour
+
 type Event struct {
-	SQLAPI sqlapi.ISQLAPI
-	ctx    context.Context
+	SQLAPI  sqlapi.ISQLAPI
+	Sources *sources.Registry
+	log     *slog.Logger
+	ctx     context.Context
 }
 
 var (
 	ErrUserAlreadyAdded  = errors.New("such a user has already been added")
 	ErrLinkAlreadyExists = errors.New("such a link already exists")
 	ErrLinkNotDB         = errors.New("there is no such link in the database")
+	ErrRefNotFound       = errors.New("the user does not track such a link")
+	ErrBadTimezone       = errors.New("unknown time zone")
+	ErrUserNotFound      = errors.New("there is no such user in the database")
 )
 
-func NewBotEvents(sqlapi sqlapi.ISQLAPI, ctx context.Context) *Event {
+func NewBotEvents(sqlapi sqlapi.ISQLAPI, sources *sources.Registry, log *slog.Logger, ctx context.Context) *Event {
 	return &Event{
-		SQLAPI: sqlapi,
-		ctx:    ctx,
+		SQLAPI:  sqlapi,
+		Sources: sources,
+		log:     log,
+		ctx:     ctx,
 	}
 }
 
+// WithLogger возвращает копию Event, которая пишет в журнал log. Так в
+// записи попадают поля обновления, в рамках которого вызван метод.
+func (api *Event) WithLogger(log *slog.Logger) IEvent {
+	scoped := *api
+	scoped.log = log
+
+	return &scoped
+}
+
 func (api *Event) AddNewUser(userN string, userID int, chatID int64) error {
 	user, err := api.getUser(userID)
 	if err != nil {
@@ -56,20 +132,30 @@ func (api *Event) AddNewUser(userN string, userID int, chatID int64) error {
 	return nil
 }
 
+// AddNewRefUserLink подписывает пользователя на ссылку. Ссылка приводится
+// к единому виду площадкой, которой она принадлежит; ссылки на
+// неподдерживаемые площадки отклоняются с ошибкой sources.ErrUnsupported.
 func (api *Event) AddNewRefUserLink(userID int, link string) error {
+	link, kind, err := api.Sources.Canonicalize(link)
+	if err != nil {
+		return e.Wrap("canonicalize link failed with an error: ", err)
+	}
+
 	linkRow, err := api.GetLinkByLink(link)
 	if err != nil {
 		return e.Wrap("get link by link failed with an error: ", err)
 	}
 
 	if linkRow == nil {
-		linkRow.LinkID, err = api.saveLink(link)
+		linkID, err := api.saveLink(link, kind)
 		if err != nil {
 			return e.Wrap("save link by link failed with an error: ", err)
 		}
+
+		linkRow = &sqlapi.LinkRow{LinkID: linkID, Link: link, Kind: kind}
 	}
 
-	refRow, err := api.getRefByIDLinkUser(userID, link)
+	refRow, err := api.getRefByIDLinkUser(userID, linkRow.LinkID)
 	if err != nil {
 		return e.Wrap("get user link by id failed with an error: ", err)
 	}
@@ -78,16 +164,18 @@ func (api *Event) AddNewRefUserLink(userID int, link string) error {
 		return e.Wrap("New ref user link: ", ErrLinkAlreadyExists)
 	}
 
-	_, err = api.createRefUserLink(userID, link)
+	_, err = api.createRefUserLink(userID, linkRow.LinkID)
 	if err != nil {
 		return e.Wrap("create ref user link failed with an error: ", err)
 	}
 
+	api.log.Info("subscription added", logger.KeyLinkID, linkRow.LinkID, logger.KeyLink, link)
+
 	return nil
 }
 
 func (api *Event) RemoveRefUserLink(userID int, link string) error {
-	linkRow, err := api.GetLinkByLink(link)
+	linkRow, err := api.findLink(link)
 	if err != nil {
 		return e.Wrap("get link by link failed with an error: ", err)
 	}
@@ -101,6 +189,8 @@ func (api *Event) RemoveRefUserLink(userID int, link string) error {
 		return e.Wrap("delete ref userID linkID: ", err)
 	}
 
+	api.log.Info("subscription removed", logger.KeyLinkID, linkRow.LinkID, logger.KeyLink, linkRow.Link)
+
 	return nil
 }
 
@@ -113,6 +203,574 @@ func (api *Event) GetAllUserLinks(userID int) ([]*sqlapi.LinkRow, error) {
 	return refRow, nil
 }
 
+func (api *Event) SetPriceAlert(userID int, link string, enabled bool, below int) error {
+	linkRow, err := api.getTrackedLink(userID, link)
+	if err != nil {
+		return e.Wrap("get tracked link failed with an error: ", err)
+	}
+
+	err = api.SQLAPI.SetPriceAlert(api.ctx, userID, linkRow.LinkID, enabled, below)
+	if err != nil {
+		return e.Wrap("set price alert failed with an error: ", err)
+	}
+
+	return nil
+}
+
+func (api *Event) SetChapterAlert(userID int, link string, enabled bool) error {
+	linkRow, err := api.getTrackedLink(userID, link)
+	if err != nil {
+		return e.Wrap("get tracked link failed with an error: ", err)
+	}
+
+	err = api.SQLAPI.SetChapterAlert(api.ctx, userID, linkRow.LinkID, enabled)
+	if err != nil {
+		return e.Wrap("set chapter alert failed with an error: ", err)
+	}
+
+	return nil
+}
+
+func (api *Event) SetStatusAlert(userID int, link string, enabled bool) error {
+	linkRow, err := api.getTrackedLink(userID, link)
+	if err != nil {
+		return e.Wrap("get tracked link failed with an error: ", err)
+	}
+
+	err = api.SQLAPI.SetStatusAlert(api.ctx, userID, linkRow.LinkID, enabled)
+	if err != nil {
+		return e.Wrap("set status alert failed with an error: ", err)
+	}
+
+	return nil
+}
+
+func (api *Event) GetAllLinks() ([]*sqlapi.LinkRow, error) {
+	linkRows, err := api.SQLAPI.GetAllLinks()
+	if err != nil {
+		return nil, e.Wrap("get all links failed with an error: ", err)
+	}
+
+	return linkRows, nil
+}
+
+func (api *Event) UpdateLink(link sqlapi.LinkRow) error {
+	err := api.SQLAPI.UpdateLink(api.ctx, link)
+	if err != nil {
+		return e.Wrap("update link failed with an error: ", err)
+	}
+
+	return nil
+}
+
+func (api *Event) GetSubscribers(linkID string) ([]*sqlapi.SubscriberRow, error) {
+	subRows, err := api.SQLAPI.GetSubscribers(linkID)
+	if err != nil {
+		return nil, e.Wrap("get subscribers failed with an error: ", err)
+	}
+
+	return subRows, nil
+}
+
+func (api *Event) GetLastPrice(linkID string) (*sqlapi.PriceRow, error) {
+	priceRow, err := api.SQLAPI.GetLastPrice(linkID)
+	if err != nil {
+		return nil, e.Wrap("get last price failed with an error: ", err)
+	}
+
+	return priceRow, nil
+}
+
+func (api *Event) AddPrice(linkID string, price, discount int, free bool) error {
+	priceRow := sqlapi.PriceRow{
+		PriceID:  getUUID(),
+		LinkID:   linkID,
+		Price:    price,
+		Discount: discount,
+		Free:     free,
+	}
+
+	err := api.SQLAPI.AddPrice(api.ctx, priceRow)
+	if err != nil {
+		return e.Wrap("add price failed with an error: ", err)
+	}
+
+	return nil
+}
+
+func (api *Event) GetLinkItems(linkID string) ([]*sqlapi.ItemRow, error) {
+	itemRows, err := api.SQLAPI.GetLinkItems(linkID)
+	if err != nil {
+		return nil, e.Wrap("get link items failed with an error: ", err)
+	}
+
+	return itemRows, nil
+}
+
+func (api *Event) AddLinkItem(item sqlapi.ItemRow) error {
+	item.ItemID = getUUID()
+
+	err := api.SQLAPI.AddLinkItem(api.ctx, item)
+	if err != nil {
+		return e.Wrap("add link item failed with an error: ", err)
+	}
+
+	return nil
+}
+
+func (api *Event) UpdateLinkItem(item sqlapi.ItemRow) error {
+	err := api.SQLAPI.UpdateLinkItem(api.ctx, item)
+	if err != nil {
+		return e.Wrap("update link item failed with an error: ", err)
+	}
+
+	return nil
+}
+
+func (api *Event) SetDelivery(userID int, delivery string, hour int) error {
+	err := api.SQLAPI.SetDelivery(api.ctx, userID, delivery, hour)
+	if err != nil {
+		return e.Wrap("set delivery failed with an error: ", err)
+	}
+
+	return nil
+}
+
+func (api *Event) SetLastDigest(userID int, sent time.Time) error {
+	err := api.SQLAPI.SetLastDigest(api.ctx, userID, sent)
+	if err != nil {
+		return e.Wrap("set last digest failed with an error: ", err)
+	}
+
+	return nil
+}
+
+// AddNotice откладывает оповещение до отправки сводки.
+func (api *Event) AddNotice(userID int, chatID int64, linkID, text string) error {
+	notice := sqlapi.NoticeRow{
+		NoticeID: getUUID(),
+		UserID:   userID,
+		ChatID:   chatID,
+		LinkID:   linkID,
+		Text:     text,
+	}
+
+	err := api.SQLAPI.AddNotice(api.ctx, notice)
+	if err != nil {
+		return e.Wrap("add notice failed with an error: ", err)
+	}
+
+	return nil
+}
+
+func (api *Event) GetPendingUsers() ([]*sqlapi.UserRow, error) {
+	userRows, err := api.SQLAPI.GetPendingUsers()
+	if err != nil {
+		return nil, e.Wrap("get pending users failed with an error: ", err)
+	}
+
+	return userRows, nil
+}
+
+func (api *Event) GetNotices(userID int) ([]*sqlapi.NoticeRow, error) {
+	noticeRows, err := api.SQLAPI.GetNotices(userID)
+	if err != nil {
+		return nil, e.Wrap("get notices failed with an error: ", err)
+	}
+
+	return noticeRows, nil
+}
+
+func (api *Event) RemoveNotices(userID int, before time.Time) error {
+	err := api.SQLAPI.RemoveNotices(api.ctx, userID, before)
+	if err != nil {
+		return e.Wrap("remove notices failed with an error: ", err)
+	}
+
+	return nil
+}
+
+func (api *Event) GetUser(userID int) (*sqlapi.UserRow, error) {
+	userRow, err := api.getUser(userID)
+	if err != nil {
+		return nil, e.Wrap("get user failed with an error: ", err)
+	}
+
+	return userRow, nil
+}
+
+func (api *Event) SetTimezone(userID int, timezone string) error {
+	if !tz.Valid(timezone) {
+		return e.Wrap(timezone, ErrBadTimezone)
+	}
+
+	err := api.SQLAPI.SetTimezone(api.ctx, userID, timezone)
+	if err != nil {
+		return e.Wrap("set timezone failed with an error: ", err)
+	}
+
+	return nil
+}
+
+func (api *Event) SetLanguage(userID int, lang string) error {
+	err := api.SQLAPI.SetLang(api.ctx, userID, lang)
+	if err != nil {
+		return e.Wrap("set lang failed with an error: ", err)
+	}
+
+	return nil
+}
+
+// SetBanned блокирует пользователя или снимает блокировку. Заблокированному
+// пользователю бот не отвечает и не присылает оповещений.
+func (api *Event) SetBanned(userID int, banned bool) error {
+	user, err := api.getUser(userID)
+	if err != nil {
+		return e.Wrap("get user failed with an error: ", err)
+	}
+
+	if user == nil {
+		return e.Wrap("Set banned: ", ErrUserNotFound)
+	}
+
+	err = api.SQLAPI.SetBanned(api.ctx, userID, banned)
+	if err != nil {
+		return e.Wrap("set banned failed with an error: ", err)
+	}
+
+	api.log.Info("user ban changed", "banned_user_id", userID, "banned", banned)
+
+	return nil
+}
+
+// SetBlocked отмечает, что пользователь заблокировал бота или удалил его из
+// группы: оповещения ему больше не отправляются, отложенные удаляются.
+// Для неизвестного пользователя ничего не делает.
+func (api *Event) SetBlocked(userID int, blocked bool) error {
+	user, err := api.getUser(userID)
+	if err != nil {
+		return e.Wrap("get user failed with an error: ", err)
+	}
+
+	if user == nil || user.Blocked == blocked {
+		return nil
+	}
+
+	err = api.SQLAPI.SetBlocked(api.ctx, userID, blocked)
+	if err != nil {
+		return e.Wrap("set blocked failed with an error: ", err)
+	}
+
+	if blocked {
+		err = api.SQLAPI.RemoveNotices(api.ctx, userID, time.Now())
+		if err != nil {
+			return e.Wrap("remove notices failed with an error: ", err)
+		}
+	}
+
+	api.log.Info("user blocked state changed", "blocked", blocked)
+
+	return nil
+}
+
+// JoinChat сохраняет группу, в которую добавили бота, или снимает с нее
+// отметку о блокировке, если бота добавили повторно.
+func (api *Event) JoinChat(name string, userID int, chatID int64) error {
+	user, err := api.getUser(userID)
+	if err != nil {
+		return e.Wrap("get user failed with an error: ", err)
+	}
+
+	if user == nil {
+		err = api.saveUser(name, userID, chatID)
+		if err != nil {
+			return e.Wrap("save chat failed with an error: ", err)
+		}
+
+		api.log.Info("bot added to chat")
+
+		return nil
+	}
+
+	return api.SetBlocked(userID, false)
+}
+
+// LeaveChannel возвращает оповещения, которые приходили в канал, из
+// которого удалили бота, в чаты владельцев подписок.
+func (api *Event) LeaveChannel(chatID int64) error {
+	n, err := api.SQLAPI.ResetChatID(api.ctx, chatID)
+	if err != nil {
+		return e.Wrap("reset chat id failed with an error: ", err)
+	}
+
+	api.log.Info("bot removed from channel", "owners", n)
+
+	return nil
+}
+
+// GetActiveUsers возвращает пользователей, которые не заблокированы.
+func (api *Event) GetActiveUsers() ([]*sqlapi.UserRow, error) {
+	userRows, err := api.SQLAPI.GetActiveUsers()
+	if err != nil {
+		return nil, e.Wrap("get active users failed with an error: ", err)
+	}
+
+	return userRows, nil
+}
+
+// GetStats возвращает число пользователей, ссылок и подписок.
+func (api *Event) GetStats() (*sqlapi.StatsRow, error) {
+	statsRow, err := api.SQLAPI.GetStats()
+	if err != nil {
+		return nil, e.Wrap("get stats failed with an error: ", err)
+	}
+
+	return statsRow, nil
+}
+
+// LastUpdateID возвращает идентификатор обновления Telegram, обработанного
+// последним. Если за updateRetention обновлений не было, возвращает 0:
+// нумерация в Telegram могла начаться заново.
+func (api *Event) LastUpdateID() (int, error) {
+	updateID, err := api.SQLAPI.GetLastUpdateID(api.ctx, time.Now().Add(-updateRetention))
+	if err != nil {
+		return 0, e.Wrap("get last update id failed with an error: ", err)
+	}
+
+	return updateID, nil
+}
+
+// ClaimUpdate отмечает обновление обработанным до начала обработки и
+// возвращает false, если оно уже отмечено. Обновление обрабатывается не
+// больше одного раза: если бот упадет посреди обработки, после перезапуска
+// оно будет пропущено, зато ссылка не добавится и не удалится дважды.
+func (api *Event) ClaimUpdate(updateID int) (bool, error) {
+	claimed, err := api.SQLAPI.ClaimUpdate(api.ctx, updateID)
+	if err != nil {
+		return false, e.Wrap("claim update failed with an error: ", err)
+	}
+
+	return claimed, nil
+}
+
+// PruneUpdates удаляет отметки об обновлениях старше updateRetention.
+func (api *Event) PruneUpdates() error {
+	err := api.SQLAPI.PruneUpdates(api.ctx, time.Now().Add(-updateRetention))
+	if err != nil {
+		return e.Wrap("prune updates failed with an error: ", err)
+	}
+
+	return nil
+}
+
+func (api *Event) SetQuietHours(userID int, start, end int) error {
+	err := api.SQLAPI.SetQuietHours(api.ctx, userID, start, end)
+	if err != nil {
+		return e.Wrap("set quiet hours failed with an error: ", err)
+	}
+
+	return nil
+}
+
+// GetSubscription возвращает подписку пользователя на ссылку.
+func (api *Event) GetSubscription(userID int, link string) (*sqlapi.RefRow, error) {
+	linkRow, err := api.getTrackedLink(userID, link)
+	if err != nil {
+		return nil, e.Wrap("get tracked link failed with an error: ", err)
+	}
+
+	refRow, err := api.getRefByIDLinkUser(userID, linkRow.LinkID)
+	if err != nil {
+		return nil, e.Wrap("get user link by id failed with an error: ", err)
+	}
+
+	refRow.Link = linkRow.Link
+
+	return refRow, nil
+}
+
+// GetSubscriptionByID возвращает подписку по идентификатору, только если
+// она принадлежит пользователю.
+func (api *Event) GetSubscriptionByID(userID int, refID string) (*sqlapi.RefRow, error) {
+	refRow, err := api.SQLAPI.GetRefByID(refID)
+	if err != nil {
+		return nil, e.Wrap("get ref by id failed with an error: ", err)
+	}
+
+	if refRow == nil || refRow.UserID != userID {
+		return nil, e.Wrap("ref row nil: ", ErrRefNotFound)
+	}
+
+	return refRow, nil
+}
+
+func (api *Event) UpdateSubscription(ref sqlapi.RefRow) error {
+	err := api.SQLAPI.UpdateRefFilters(api.ctx, ref)
+	if err != nil {
+		return e.Wrap("update subscription failed with an error: ", err)
+	}
+
+	return nil
+}
+
+func (api *Event) SetPaused(userID int, link string, paused bool) error {
+	linkRow, err := api.getTrackedLink(userID, link)
+	if err != nil {
+		return e.Wrap("get tracked link failed with an error: ", err)
+	}
+
+	err = api.SQLAPI.SetPaused(api.ctx, userID, linkRow.LinkID, paused)
+	if err != nil {
+		return e.Wrap("set paused failed with an error: ", err)
+	}
+
+	return nil
+}
+
+// SnoozeLink откладывает оповещения по ссылке до until. Время в прошлом
+// снимает отсрочку.
+func (api *Event) SnoozeLink(userID int, link string, until time.Time) error {
+	linkRow, err := api.getTrackedLink(userID, link)
+	if err != nil {
+		return e.Wrap("get tracked link failed with an error: ", err)
+	}
+
+	err = api.SQLAPI.SetSnooze(api.ctx, userID, linkRow.LinkID, until)
+	if err != nil {
+		return e.Wrap("set snooze failed with an error: ", err)
+	}
+
+	return nil
+}
+
+// SnoozeAll откладывает все оповещения пользователя до until.
+func (api *Event) SnoozeAll(userID int, until time.Time) error {
+	err := api.SQLAPI.SetUserSnooze(api.ctx, userID, until)
+	if err != nil {
+		return e.Wrap("set user snooze failed with an error: ", err)
+	}
+
+	return nil
+}
+
+// RemoveUser удаляет пользователя со всеми подписками и отложенными
+// оповещениями, а затем ссылки, на которые больше никто не подписан.
+func (api *Event) RemoveUser(userID int) error {
+	err := api.SQLAPI.RemoveUser(userID)
+	if err != nil {
+		return e.Wrap("remove user failed with an error: ", err)
+	}
+
+	n, err := api.SQLAPI.RemoveOrphanLinks(api.ctx)
+	if err != nil {
+		return e.Wrap("remove orphan links failed with an error: ", err)
+	}
+
+	api.log.Info("user removed", "orphan_links", n)
+
+	return nil
+}
+
+func (api *Event) GetSubscriptions(userID int) ([]*sqlapi.RefRow, error) {
+	refRows, err := api.SQLAPI.GetUserRefs(userID)
+	if err != nil {
+		return nil, e.Wrap("get user refs failed with an error: ", err)
+	}
+
+	return refRows, nil
+}
+
+// ImportSubscription подписывает пользователя на ссылку из ref.Link и
+// переносит на подписку настройки оповещений из ref. Уже отслеживаемые
+// ссылки не меняются и возвращают ErrLinkAlreadyExists.
+func (api *Event) ImportSubscription(userID int, ref sqlapi.RefRow) error {
+	if err := api.AddNewRefUserLink(userID, ref.Link); err != nil {
+		return e.Wrap("add new ref user link failed with an error: ", err)
+	}
+
+	refRow, err := api.GetSubscription(userID, ref.Link)
+	if err != nil {
+		return e.Wrap("get subscription failed with an error: ", err)
+	}
+
+	ref.RefID = refRow.RefID
+	ref.LinkID = refRow.LinkID
+	ref.UserID = userID
+
+	err = api.SQLAPI.UpdateRefFilters(api.ctx, ref)
+	if err != nil {
+		return e.Wrap("update ref filters failed with an error: ", err)
+	}
+
+	err = api.SQLAPI.SetPriceAlert(api.ctx, userID, ref.LinkID, ref.PriceAlert, ref.PriceBelow)
+	if err != nil {
+		return e.Wrap("set price alert failed with an error: ", err)
+	}
+
+	return nil
+}
+
+// SetChatTarget задает чат или канал, куда приходят оповещения владельца
+// подписок.
+func (api *Event) SetChatTarget(userID int, chatID int64) error {
+	err := api.SQLAPI.SetChatID(api.ctx, userID, chatID)
+	if err != nil {
+		return e.Wrap("set chat id failed with an error: ", err)
+	}
+
+	return nil
+}
+
+// MigrateChat переносит подписки группы на супергруппу, в которую она
+// превратилась: у супергруппы новый идентификатор чата.
+func (api *Event) MigrateChat(fromChatID, toChatID int64) error {
+	err := api.SQLAPI.MigrateUser(api.ctx, fromChatID, toChatID)
+	if err != nil {
+		return e.Wrap("migrate user failed with an error: ", err)
+	}
+
+	api.log.Info("chat migrated", "from_chat_id", fromChatID, "to_chat_id", toChatID)
+
+	return nil
+}
+
+// findLink ищет ссылку сначала в едином виде, а затем так, как ее прислал
+// пользователь: ссылки, сохраненные до приведения к единому виду, хранятся
+// как есть.
+func (api *Event) findLink(link string) (*sqlapi.LinkRow, error) {
+	if canonical, _, err := api.Sources.Canonicalize(link); err == nil {
+		linkRow, err := api.GetLinkByLink(canonical)
+		if err != nil || linkRow != nil {
+			return linkRow, err
+		}
+	}
+
+	return api.GetLinkByLink(link)
+}
+
+// getTrackedLink возвращает ссылку, только если пользователь ее отслеживает.
+func (api *Event) getTrackedLink(userID int, link string) (*sqlapi.LinkRow, error) {
+	linkRow, err := api.findLink(link)
+	if err != nil {
+		return nil, e.Wrap("get link by link failed with an error: ", err)
+	}
+
+	if linkRow == nil {
+		return nil, e.Wrap("link row nil: ", ErrLinkNotDB)
+	}
+
+	refRow, err := api.getRefByIDLinkUser(userID, linkRow.LinkID)
+	if err != nil {
+		return nil, e.Wrap("get user link by id failed with an error: ", err)
+	}
+
+	if refRow == nil {
+		return nil, e.Wrap("ref row nil: ", ErrRefNotFound)
+	}
+
+	return linkRow, nil
+}
+
 func (api *Event) getRefByIDLinkUser(userID int, linkID string) (*sqlapi.RefRow, error) {
 	refRow, err := api.SQLAPI.GetRefByIDLinkUser(userID, linkID)
 	if err != nil {
@@ -168,9 +826,9 @@ func (api *Event) saveUser(userN string, userID int, chatID int64) error {
 	return nil
 }
 
-func (api *Event) saveLink(link string) (string, error) {
+func (api *Event) saveLink(link, kind string) (string, error) {
 	uuid := getUUID()
-	err := api.SQLAPI.AddLink(api.ctx, link, uuid)
+	err := api.SQLAPI.AddLink(api.ctx, link, uuid, kind)
 	if err != nil {
 		return "", e.Wrap("save new link failed with an error: ", err)
 	}
@@ -0,0 +1,129 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/EfimoffN/authorBot/lib/logger"
+)
+
+// Состояния компонентов и бота в целом.
+const (
+	StatusOK   = "ok"
+	StatusFail = "fail"
+)
+
+// defaultTimeout — сколько ждать ответа всех проверок готовности.
+const defaultTimeout = 5 * time.Second
+
+// Check проверяет один компонент бота и возвращает ошибку, если он не
+// готов к работе.
+type Check func(ctx context.Context) error
+
+// Report — ответ /healthz и /readyz.
+type Report struct {
+	Status     string               `json:"status"`
+	Components map[string]Component `json:"components,omitempty"`
+}
+
+// Component — состояние одного компонента.
+type Component struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Checker отвечает на проверки живости и готовности бота.
+type Checker struct {
+	checks  map[string]Check
+	timeout time.Duration
+}
+
+func NewChecker() *Checker {
+	return &Checker{
+		checks:  map[string]Check{},
+		timeout: defaultTimeout,
+	}
+}
+
+// Add добавляет проверку готовности компонента name.
+func (c *Checker) Add(name string, check Check) {
+	c.checks[name] = check
+}
+
+// Ready выполняет все проверки одновременно. Бот готов, если готовы все
+// компоненты; проверка, не успевшая до конца ctx, считается неудачной.
+func (c *Checker) Ready(ctx context.Context) Report {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	report := Report{Status: StatusOK, Components: map[string]Component{}}
+	mu := sync.Mutex{}
+	wg := sync.WaitGroup{}
+
+	for name, check := range c.checks {
+		wg.Add(1)
+
+		go func(name string, check Check) {
+			defer wg.Done()
+
+			component := Component{Status: StatusOK}
+			if err := run(ctx, check); err != nil {
+				component = Component{Status: StatusFail, Error: err.Error()}
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			report.Components[name] = component
+			if component.Status != StatusOK {
+				report.Status = StatusFail
+			}
+		}(name, check)
+	}
+
+	wg.Wait()
+
+	return report
+}
+
+// run не дает проверке, которая не следит за контекстом, задержать ответ.
+func run(ctx context.Context, check Check) error {
+	done := make(chan error, 1)
+
+	go func() {
+		done <- check(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Healthz отвечает, что процесс жив и обрабатывает HTTP-запросы.
+func (c *Checker) Healthz(w http.ResponseWriter, r *http.Request) {
+	writeReport(w, Report{Status: StatusOK})
+}
+
+// Readyz отвечает 200, если все компоненты готовы, и 503, если нет.
+func (c *Checker) Readyz(w http.ResponseWriter, r *http.Request) {
+	writeReport(w, c.Ready(r.Context()))
+}
+
+func writeReport(w http.ResponseWriter, report Report) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if report.Status != StatusOK {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		slog.Error("health write report", logger.Err(err))
+	}
+}
@@ -0,0 +1,87 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func Test_Readyz(t *testing.T) {
+	ok := func(ctx context.Context) error { return nil }
+	fail := func(ctx context.Context) error { return errors.New("connection refused") }
+	hang := func(ctx context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	}
+
+	tests := []struct {
+		name       string
+		checks     map[string]Check
+		wantCode   int
+		wantStatus map[string]string
+	}{
+		{
+			name:       "all ready",
+			checks:     map[string]Check{"db": ok, "telegram": ok},
+			wantCode:   http.StatusOK,
+			wantStatus: map[string]string{"db": StatusOK, "telegram": StatusOK},
+		},
+		{
+			name:       "component failed",
+			checks:     map[string]Check{"db": fail, "telegram": ok},
+			wantCode:   http.StatusServiceUnavailable,
+			wantStatus: map[string]string{"db": StatusFail, "telegram": StatusOK},
+		},
+		{
+			name:       "component timed out",
+			checks:     map[string]Check{"watcher": hang},
+			wantCode:   http.StatusServiceUnavailable,
+			wantStatus: map[string]string{"watcher": StatusFail},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewChecker()
+			c.timeout = 50 * time.Millisecond
+
+			for name, check := range tt.checks {
+				c.Add(name, check)
+			}
+
+			rec := httptest.NewRecorder()
+			c.Readyz(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+			if rec.Code != tt.wantCode {
+				t.Errorf("Readyz() code = %d, want %d", rec.Code, tt.wantCode)
+			}
+
+			report := Report{}
+			if err := json.NewDecoder(rec.Body).Decode(&report); err != nil {
+				t.Fatalf("decode report: %v", err)
+			}
+
+			for name, want := range tt.wantStatus {
+				if got := report.Components[name].Status; got != want {
+					t.Errorf("Readyz() %s = %q, want %q", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func Test_Healthz(t *testing.T) {
+	c := NewChecker()
+	c.Add("db", func(ctx context.Context) error { return errors.New("down") })
+
+	rec := httptest.NewRecorder()
+	c.Healthz(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("Healthz() code = %d, want %d", rec.Code, http.StatusOK)
+	}
+}
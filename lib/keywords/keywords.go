@@ -0,0 +1,49 @@
+package keywords
+
+import "strings"
+
+// Split разбирает список слов через запятую: приводит слова к нижнему
+// регистру и убирает пустые.
+func Split(list string) []string {
+	words := []string{}
+
+	for _, w := range strings.Split(list, ",") {
+		if w = strings.ToLower(strings.TrimSpace(w)); w != "" {
+			words = append(words, w)
+		}
+	}
+
+	return words
+}
+
+// Normalize приводит список слов через запятую к виду, в котором он
+// хранится в подписке.
+func Normalize(list string) string {
+	return strings.Join(Split(list), ", ")
+}
+
+// Match проверяет текст по ключевым словам подписки. Если заданы слова
+// для включения, текст должен содержать хотя бы одно из них, и ни одного
+// слова для исключения.
+func Match(include, exclude, text string) bool {
+	text = strings.ToLower(text)
+
+	for _, w := range Split(exclude) {
+		if strings.Contains(text, w) {
+			return false
+		}
+	}
+
+	words := Split(include)
+	if len(words) == 0 {
+		return true
+	}
+
+	for _, w := range words {
+		if strings.Contains(text, w) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,65 @@
+package keywords
+
+import "testing"
+
+func Test_Normalize(t *testing.T) {
+	tests := []struct {
+		name string
+		list string
+		want string
+	}{
+		{name: "empty", list: " , ", want: ""},
+		{name: "words", list: "Том,  продолжение ,,ЦИКЛ", want: "том, продолжение, цикл"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Normalize(tt.list); got != tt.want {
+				t.Errorf("Normalize() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_Match(t *testing.T) {
+	tests := []struct {
+		name    string
+		include string
+		exclude string
+		text    string
+		want    bool
+	}{
+		{
+			name: "no filters",
+			text: "Новости о цикле",
+			want: true,
+		},
+		{
+			name:    "include matched",
+			include: "цикл, том",
+			text:    "Новости о Цикле",
+			want:    true,
+		},
+		{
+			name:    "include not matched",
+			include: "том",
+			text:    "Новости о цикле",
+			want:    false,
+		},
+		{
+			name:    "exclude wins",
+			include: "цикл",
+			exclude: "розыгрыш",
+			text:    "Розыгрыш книг цикла",
+			want:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Match(tt.include, tt.exclude, tt.text); got != tt.want {
+				t.Errorf("Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
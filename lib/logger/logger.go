@@ -0,0 +1,105 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"github.com/EfimoffN/authorBot/lib/redact"
+)
+
+// Форматы вывода журнала.
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Имена полей, которыми записи журнала привязываются к обновлению,
+// пользователю и ссылке.
+const (
+	KeyUpdateID = "update_id"
+	KeyUserID   = "user_id"
+	KeyChatID   = "chat_id"
+	KeyCommand  = "command"
+	KeyLinkID   = "link_id"
+	KeyLink     = "link"
+	KeyError    = "error"
+)
+
+// New создает журнал с уровнем level и форматом format (text или json,
+// пустой — text). Чтобы менять уровень на ходу, передайте *slog.LevelVar.
+// Значения secrets, токены ботов и пароли баз в журнал не попадают.
+func New(w io.Writer, level slog.Leveler, format string, secrets ...string) (*slog.Logger, error) {
+	opts := &slog.HandlerOptions{
+		Level:       level,
+		ReplaceAttr: redactAttr(redact.New(secrets...)),
+	}
+
+	switch strings.ToLower(format) {
+	case "", FormatText:
+		return slog.New(slog.NewTextHandler(w, opts)), nil
+	case FormatJSON:
+		return slog.New(slog.NewJSONHandler(w, opts)), nil
+	default:
+		return nil, fmt.Errorf("unknown log format %q", format)
+	}
+}
+
+// ParseLevel разбирает уровень журнала. Пустой уровень — info.
+func ParseLevel(level string) (slog.Level, error) {
+	switch strings.ToLower(level) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return slog.LevelInfo, fmt.Errorf("unknown log level %q", level)
+	}
+}
+
+// redactAttr скрывает секреты в строковых полях, сообщениях и ошибках.
+func redactAttr(r *redact.Redactor) func(groups []string, a slog.Attr) slog.Attr {
+	return func(groups []string, a slog.Attr) slog.Attr {
+		switch a.Value.Kind() {
+		case slog.KindString:
+			a.Value = slog.StringValue(r.String(a.Value.String()))
+		case slog.KindAny:
+			if err, ok := a.Value.Any().(error); ok {
+				a.Value = slog.StringValue(r.String(err.Error()))
+			}
+		}
+
+		return a
+	}
+}
+
+// Err возвращает поле с текстом ошибки.
+func Err(err error) slog.Attr {
+	return slog.Any(KeyError, err)
+}
+
+// BotLogger передает журнал библиотеки Telegram в slog на уровне Debug.
+// Библиотека пишет в журнал только запросы и ответы API в режиме отладки,
+// поэтому ее режим отладки можно не выключать: записи отбрасывает уровень
+// журнала, который меняется без перезапуска.
+type BotLogger struct {
+	Log *slog.Logger
+}
+
+func (l BotLogger) Println(v ...interface{}) {
+	if l.Log.Enabled(context.Background(), slog.LevelDebug) {
+		l.Log.Debug(strings.TrimSpace(fmt.Sprintln(v...)))
+	}
+}
+
+func (l BotLogger) Printf(format string, v ...interface{}) {
+	if l.Log.Enabled(context.Background(), slog.LevelDebug) {
+		l.Log.Debug(strings.TrimSpace(fmt.Sprintf(format, v...)))
+	}
+}
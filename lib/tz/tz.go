@@ -0,0 +1,42 @@
+package tz
+
+import (
+	"time"
+	// Встроенная база часовых поясов: в контейнере ее может не быть.
+	_ "time/tzdata"
+)
+
+// Default — часовой пояс пользователя, пока он не выбрал свой.
+const Default = "Europe/Moscow"
+
+const layout = "02.01.2006 15:04"
+
+// Valid сообщает, известен ли часовой пояс с таким именем.
+func Valid(name string) bool {
+	if name == "" {
+		return false
+	}
+
+	_, err := time.LoadLocation(name)
+
+	return err == nil
+}
+
+// Location возвращает часовой пояс по имени, а для пустого или
+// неизвестного имени — пояс по умолчанию.
+func Location(name string) *time.Location {
+	if loc, err := time.LoadLocation(name); err == nil && name != "" {
+		return loc
+	}
+
+	if loc, err := time.LoadLocation(Default); err == nil {
+		return loc
+	}
+
+	return time.UTC
+}
+
+// Format выводит время в часовом поясе пользователя.
+func Format(t time.Time, name string) string {
+	return t.In(Location(name)).Format(layout)
+}
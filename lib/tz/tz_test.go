@@ -0,0 +1,40 @@
+package tz
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_Format(t *testing.T) {
+	ts := time.Date(2023, 5, 10, 21, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		zone string
+		want string
+	}{
+		{
+			name: "moscow",
+			zone: "Europe/Moscow",
+			want: "11.05.2023 00:30",
+		},
+		{
+			name: "utc",
+			zone: "UTC",
+			want: "10.05.2023 21:30",
+		},
+		{
+			name: "unknown falls back to default",
+			zone: "Mars/Olympus",
+			want: "11.05.2023 00:30",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Format(ts, tt.zone); got != tt.want {
+				t.Errorf("Format() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
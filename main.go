@@ -3,26 +3,32 @@ package main
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
+	"net/http"
 	"os"
+	"time"
 
+	"github.com/EfimoffN/authorBot/atapi"
 	"github.com/EfimoffN/authorBot/commands"
 	"github.com/EfimoffN/authorBot/config"
 	"github.com/EfimoffN/authorBot/events"
+	"github.com/EfimoffN/authorBot/health"
 	"github.com/EfimoffN/authorBot/lib/e"
+	"github.com/EfimoffN/authorBot/lib/logger"
+	"github.com/EfimoffN/authorBot/metrics"
+	"github.com/EfimoffN/authorBot/notifier"
+	"github.com/EfimoffN/authorBot/parser"
+	"github.com/EfimoffN/authorBot/render"
 	"github.com/EfimoffN/authorBot/service"
+	"github.com/EfimoffN/authorBot/sources"
+	"github.com/EfimoffN/authorBot/sources/authortoday"
 	"github.com/EfimoffN/authorBot/sqlapi"
+	"github.com/EfimoffN/authorBot/watcher"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
 	"github.com/urfave/cli/v2"
 )
 
 func main() {
-
-	pgPass := os.Getenv("pgPass")
-	fmt.Println(pgPass)
-
-	return
-
 	app := &cli.App{
 		Name: "authorBot",
 		Flags: []cli.Flag{
@@ -32,14 +38,63 @@ func main() {
 				Usage:   "Load configuration",
 			},
 		},
+		Commands: []*cli.Command{
+			{
+				Name:  "config",
+				Usage: "Inspect configuration",
+				Subcommands: []*cli.Command{
+					{
+						Name:  "print",
+						Usage: "Print the effective configuration with secrets masked",
+						Action: func(cCtx *cli.Context) error {
+							cfg, err := config.Read(cCtx.String("config"))
+							if err != nil {
+								return e.Wrap("Read config: ", err)
+							}
+
+							return cfg.Print(os.Stdout)
+						},
+					},
+					{
+						Name:  "check",
+						Usage: "Validate the configuration and report every problem found",
+						Action: func(cCtx *cli.Context) error {
+							if _, err := config.Load(cCtx.String("config")); err != nil {
+								return cli.Exit(err.Error(), 1)
+							}
+
+							fmt.Println("configuration is valid")
+
+							return nil
+						},
+					},
+				},
+			},
+		},
 		Action: func(cCtx *cli.Context) error {
 			ctx := context.Background()
 
-			cfg, err := config.CreateConfig(cCtx.String("config"))
+			cfg, err := config.Load(cCtx.String("config"))
+			if err != nil {
+				return e.Wrap("Load config: ", err)
+			}
+
+			lvl, err := logger.ParseLevel(cfg.LogLevel)
+			if err != nil {
+				return e.Wrap("parse log level: ", err)
+			}
+
+			// Уровень журнала меняется по SIGHUP, см. service.Reloader.
+			level := &slog.LevelVar{}
+			level.Set(lvl)
+
+			log, err := logger.New(os.Stderr, level, cfg.LogFormat, cfg.Secrets()...)
 			if err != nil {
-				return e.Wrap("Create config: ", err)
+				return e.Wrap("create logger: ", err)
 			}
 
+			slog.SetDefault(log)
+
 			db, err := sqlapi.ConnectDB(cfg.ConnectPostgres)
 			if err != nil {
 				return e.Wrap("connect DB: ", err)
@@ -48,16 +103,79 @@ func main() {
 
 			sqlAPI := sqlapi.NewSQLAPI(db)
 
-			event := events.NewBotEvents(sqlAPI, ctx)
+			prs := parser.NewParser()
+			api := atapi.NewClient(cfg.APIURL, cfg.APIToken)
+			registry := sources.NewRegistry(authortoday.NewSource(prs, api, cfg.APIKinds))
+
+			event := events.NewBotEvents(sqlAPI, registry, log, ctx)
 
 			bot, err := tgbotapi.NewBotAPI(cfg.BotToken)
 			if err != nil {
 				return e.Wrap("New bot API: ", err)
 			}
 
+			if err := tgbotapi.SetLogger(logger.BotLogger{Log: log.With("component", "telegram")}); err != nil {
+				return e.Wrap("set bot logger: ", err)
+			}
+
+			// Записи библиотеки идут на уровне Debug, поэтому их включает и
+			// выключает уровень журнала, в том числе после SIGHUP.
 			bot.Debug = true
 
-			cmd := commands.NewBotCommands(bot, event, ctx)
+			renderer, err := render.New(cfg.TemplatesDir)
+			if err != nil {
+				return e.Wrap("load templates: ", err)
+			}
+
+			ntf := notifier.NewNotifier(bot, event, renderer, log, ctx)
+
+			go func() {
+				if err := ntf.Start(); err != nil {
+					log.Error("notifier stopped", logger.Err(err))
+				}
+			}()
+
+			wtc := watcher.NewWatcher(ntf, event, registry, renderer, time.Duration(cfg.CheckInterval)*time.Minute, log, ctx)
+
+			go func() {
+				if err := wtc.Start(); err != nil {
+					log.Error("watcher stopped", logger.Err(err))
+				}
+			}()
+
+			if cfg.BindAddr != "" {
+				checker := health.NewChecker()
+				checker.Add("db", db.PingContext)
+				checker.Add("telegram", func(ctx context.Context) error {
+					_, err := bot.GetMe()
+					return err
+				})
+				checker.Add("watcher", wtc.Alive)
+
+				mux := http.NewServeMux()
+				mux.Handle("/metrics", metrics.Handler())
+				mux.HandleFunc("/healthz", checker.Healthz)
+				mux.HandleFunc("/readyz", checker.Readyz)
+
+				go func() {
+					if err := service.ServeHTTP(cfg.BindAddr, mux); err != nil {
+						log.Error("http server stopped", logger.Err(err))
+					}
+				}()
+			}
+
+			cmd := commands.NewBotCommands(bot, event, wtc, commands.Settings{
+				AdminIDs:      cfg.AdminIDs,
+				BroadcastRate: cfg.BroadcastRate,
+			}, log, ctx)
+
+			reloader := service.NewReloader(cCtx.String("config"), cfg, level, cmd, wtc, renderer, log)
+
+			go func() {
+				if err := reloader.Start(ctx); err != nil {
+					log.Error("reloader stopped", logger.Err(err))
+				}
+			}()
 
 			err = service.Start(cmd, bot, cfg.Timeout)
 			if err != nil {
@@ -69,6 +187,7 @@ func main() {
 	}
 
 	if err := app.Run(os.Args); err != nil {
-		log.Fatal(err)
+		slog.Error("authorBot stopped", logger.Err(err))
+		os.Exit(1)
 	}
 }
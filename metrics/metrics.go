@@ -0,0 +1,124 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "authorbot"
+
+// Результаты обработки обновлений и отправки оповещений.
+const (
+	ResultOK       = "ok"
+	ResultError    = "error"
+	ResultSent     = "sent"
+	ResultFailed   = "failed"
+	ResultDeferred = "deferred"
+)
+
+var (
+	// Updates считает обработанные обновления Telegram по командам.
+	Updates = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "updates_total",
+		Help:      "Telegram updates processed, by command and result.",
+	}, []string{"command", "result"})
+
+	// HandlerDuration — время обработки обновления по командам.
+	HandlerDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "handler_duration_seconds",
+		Help:      "Time spent handling a Telegram update, by command.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"command"})
+
+	// QueryDuration — время запроса к базе по методам sqlapi.ISQLAPI.
+	QueryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Database query latency, by ISQLAPI method.",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"method"})
+
+	// Fetches считает запросы к площадке по клиенту и коду ответа.
+	Fetches = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "fetches_total",
+		Help:      "Requests to the tracked site, by client and HTTP status code.",
+	}, []string{"client", "code"})
+
+	// Changes считает обнаруженные изменения по типам.
+	Changes = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "changes_total",
+		Help:      "Changes detected by the watcher, by change type.",
+	}, []string{"type"})
+
+	// Notifications считает оповещения: отправленные, неотправленные и
+	// отложенные до сводки или конца тихих часов.
+	Notifications = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "notifications_total",
+		Help:      "Notifications by result: sent, failed or deferred.",
+	}, []string{"result"})
+
+	// QueueDepth — длина очередей: необработанных обновлений, ссылок до
+	// конца проверки, пользователей с отложенными оповещениями и рассылки.
+	QueueDepth = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "queue_depth",
+		Help:      "Items waiting in a queue, by queue.",
+	}, []string{"queue"})
+)
+
+// Очереди для QueueDepth.
+const (
+	QueueUpdates   = "updates"
+	QueueWatcher   = "watcher"
+	QueuePending   = "pending_users"
+	QueueBroadcast = "broadcast"
+)
+
+// Клиенты площадки для Fetches.
+const (
+	ClientAPI  = "api"
+	ClientPage = "page"
+)
+
+// Handler отдает метрики в формате Prometheus.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// ObserveUpdate учитывает обработанное обновление.
+func ObserveUpdate(command string, started time.Time, err error) {
+	result := ResultOK
+	if err != nil {
+		result = ResultError
+	}
+
+	Updates.WithLabelValues(command, result).Inc()
+	HandlerDuration.WithLabelValues(command).Observe(time.Since(started).Seconds())
+}
+
+// ObserveQuery учитывает запрос к базе. Вызывается через defer:
+//
+//	defer metrics.ObserveQuery("GetUserByID", time.Now())
+func ObserveQuery(method string, started time.Time) {
+	QueryDuration.WithLabelValues(method).Observe(time.Since(started).Seconds())
+}
+
+// ObserveFetch учитывает запрос к площадке. Если ответа нет, код — error.
+func ObserveFetch(client string, resp *http.Response, err error) {
+	code := ResultError
+	if err == nil && resp != nil {
+		code = strconv.Itoa(resp.StatusCode)
+	}
+
+	Fetches.WithLabelValues(client, code).Inc()
+}
@@ -0,0 +1,41 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func Test_ObserveFetch(t *testing.T) {
+	ObserveFetch(ClientPage, &http.Response{StatusCode: http.StatusNotFound}, nil)
+	ObserveFetch(ClientPage, nil, errors.New("timeout"))
+	ObserveFetch(ClientPage, nil, errors.New("timeout"))
+
+	if got := testutil.ToFloat64(Fetches.WithLabelValues(ClientPage, "404")); got != 1 {
+		t.Errorf("fetches 404 = %v, want 1", got)
+	}
+
+	if got := testutil.ToFloat64(Fetches.WithLabelValues(ClientPage, ResultError)); got != 2 {
+		t.Errorf("fetches error = %v, want 2", got)
+	}
+}
+
+func Test_ObserveUpdate(t *testing.T) {
+	ObserveUpdate("/test", time.Now(), nil)
+	ObserveUpdate("/test", time.Now(), errors.New("some error"))
+
+	if got := testutil.ToFloat64(Updates.WithLabelValues("/test", ResultOK)); got != 1 {
+		t.Errorf("updates ok = %v, want 1", got)
+	}
+
+	if got := testutil.ToFloat64(Updates.WithLabelValues("/test", ResultError)); got != 1 {
+		t.Errorf("updates error = %v, want 1", got)
+	}
+
+	if got := testutil.CollectAndCount(HandlerDuration); got != 1 {
+		t.Errorf("handler duration series = %d, want 1", got)
+	}
+}
@@ -0,0 +1,111 @@
+package notifier
+
+import (
+	"time"
+
+	"github.com/EfimoffN/authorBot/events"
+	"github.com/EfimoffN/authorBot/lib/e"
+	"github.com/EfimoffN/authorBot/lib/tz"
+	"github.com/EfimoffN/authorBot/render"
+	"github.com/EfimoffN/authorBot/sqlapi"
+)
+
+const blockSeparator = "\n\n"
+
+// digestDue сообщает, пора ли отправить пользователю сводку. Сводка
+// уходит в первую проверку после назначенного часа по времени
+// пользователя, если с прошлой сводки прошел день или неделя.
+// Пользователям с мгновенной доставкой накопившиеся оповещения
+// отправляются сразу.
+func digestDue(user *sqlapi.UserRow, now time.Time) bool {
+	now = now.In(tz.Location(user.Timezone))
+	scheduled := time.Date(now.Year(), now.Month(), now.Day(), user.DigestHour, 0, 0, 0, now.Location())
+	if scheduled.After(now) {
+		scheduled = scheduled.AddDate(0, 0, -1)
+	}
+
+	switch user.Delivery {
+	case events.DeliveryDaily:
+		return user.LastDigest.Before(scheduled)
+	case events.DeliveryWeekly:
+		return user.LastDigest.Before(scheduled.AddDate(0, 0, -6))
+	default:
+		return true
+	}
+}
+
+// digestHeader формирует заголовок сводки на языке lang для способа
+// доставки delivery.
+func digestHeader(r *render.Renderer, lang, delivery string, count int) (string, error) {
+	return r.Execute(lang, "digest_header", struct {
+		Delivery string
+		Count    int
+	}{delivery, count})
+}
+
+// digestMessages собирает оповещения, упорядоченные по ссылкам, в сводку
+// и делит ее на сообщения допустимой длины. Заголовок группы всегда
+// остается в одном сообщении с первым оповещением группы. Заголовки групп
+// формируются на языке lang.
+func digestMessages(r *render.Renderer, lang, header string, notices []*sqlapi.NoticeRow) ([]string, error) {
+	blocks := make([]string, 0, len(notices))
+	linkID := ""
+
+	for _, n := range notices {
+		text := n.Text
+
+		if n.LinkID != linkID {
+			linkID = n.LinkID
+
+			group, err := r.Execute(lang, "digest_group", struct{ Title string }{groupTitle(n)})
+			if err != nil {
+				return nil, e.Wrap("render digest group failed with an error: ", err)
+			}
+
+			text = group + "\n" + text
+		}
+
+		blocks = append(blocks, text)
+	}
+
+	if len(blocks) > 0 {
+		blocks[0] = header + blockSeparator + blocks[0]
+	}
+
+	return splitMessage(blocks, render.MaxMessageLen), nil
+}
+
+func groupTitle(n *sqlapi.NoticeRow) string {
+	if n.Title != "" {
+		return n.Title
+	}
+
+	return n.Link
+}
+
+// splitMessage склеивает блоки текста в разметке HTML в сообщения не
+// длиннее limit, не разрывая блоки без необходимости.
+func splitMessage(blocks []string, limit int) []string {
+	messages := []string{}
+	current := ""
+
+	for _, b := range blocks {
+		for _, part := range render.Split(b, limit) {
+			switch {
+			case current == "":
+				current = part
+			case render.Len(current)+render.Len(blockSeparator)+render.Len(part) <= limit:
+				current += blockSeparator + part
+			default:
+				messages = append(messages, current)
+				current = part
+			}
+		}
+	}
+
+	if current != "" {
+		messages = append(messages, current)
+	}
+
+	return messages
+}
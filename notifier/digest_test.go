@@ -0,0 +1,223 @@
+package notifier
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/EfimoffN/authorBot/events"
+	"github.com/EfimoffN/authorBot/render"
+	"github.com/EfimoffN/authorBot/sqlapi"
+)
+
+func Test_digestDue(t *testing.T) {
+	now := time.Date(2023, 5, 10, 9, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		user *sqlapi.UserRow
+		want bool
+	}{
+		{
+			name: "daily due",
+			user: &sqlapi.UserRow{Delivery: events.DeliveryDaily, DigestHour: 9, Timezone: "UTC", LastDigest: now.Add(-24 * time.Hour)},
+			want: true,
+		},
+		{
+			name: "daily already sent",
+			user: &sqlapi.UserRow{Delivery: events.DeliveryDaily, DigestHour: 9, Timezone: "UTC", LastDigest: now.Add(-20 * time.Minute)},
+			want: false,
+		},
+		{
+			name: "daily hour not reached",
+			user: &sqlapi.UserRow{Delivery: events.DeliveryDaily, DigestHour: 20, Timezone: "UTC", LastDigest: now.Add(-10 * time.Hour)},
+			want: false,
+		},
+		{
+			name: "daily missed hour",
+			user: &sqlapi.UserRow{Delivery: events.DeliveryDaily, DigestHour: 20, Timezone: "UTC", LastDigest: now.Add(-40 * time.Hour)},
+			want: true,
+		},
+		{
+			name: "weekly too early",
+			user: &sqlapi.UserRow{Delivery: events.DeliveryWeekly, DigestHour: 9, Timezone: "UTC", LastDigest: now.AddDate(0, 0, -3)},
+			want: false,
+		},
+		{
+			name: "weekly due",
+			user: &sqlapi.UserRow{Delivery: events.DeliveryWeekly, DigestHour: 9, Timezone: "UTC", LastDigest: now.AddDate(0, 0, -7)},
+			want: true,
+		},
+		{
+			name: "daily in user time zone",
+			user: &sqlapi.UserRow{Delivery: events.DeliveryDaily, DigestHour: 12, Timezone: "Europe/Moscow", LastDigest: now.Add(-24 * time.Hour)},
+			want: true,
+		},
+		{
+			name: "instant with pending",
+			user: &sqlapi.UserRow{Delivery: events.DeliveryInstant, LastDigest: now},
+			want: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := digestDue(tt.user, now); got != tt.want {
+				t.Errorf("digestDue() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_splitMessage(t *testing.T) {
+	tests := []struct {
+		name   string
+		blocks []string
+		limit  int
+		want   []string
+	}{
+		{
+			name:   "fits",
+			blocks: []string{"one", "two"},
+			limit:  20,
+			want:   []string{"one\n\ntwo"},
+		},
+		{
+			name:   "split by blocks",
+			blocks: []string{"one", "two", "three"},
+			limit:  10,
+			want:   []string{"one\n\ntwo", "three"},
+		},
+		{
+			name:   "long block by lines",
+			blocks: []string{"first line\nsecond"},
+			limit:  12,
+			want:   []string{"first line", "second"},
+		},
+		{
+			name:   "long line by runes",
+			blocks: []string{"книгакнига"},
+			limit:  4,
+			want:   []string{"книг", "акни", "га"},
+		},
+		{
+			name:   "tags and entities are kept whole",
+			blocks: []string{"<b>a &amp; b</b>"},
+			limit:  3,
+			want:   []string{"<b>a &amp;</b>", "<b> b</b>"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := splitMessage(tt.blocks, tt.limit)
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("splitMessage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_digestMessages(t *testing.T) {
+	notices := []*sqlapi.NoticeRow{}
+	for i := 0; i < 300; i++ {
+		linkID := "a"
+		if i >= 150 {
+			linkID = "b"
+		}
+
+		notices = append(notices, &sqlapi.NoticeRow{LinkID: linkID, Title: "Книга " + linkID, Text: "В книге новые главы: 1.\nhttps://author.today/work/1"})
+	}
+
+	r, err := render.New("")
+	if err != nil {
+		t.Fatalf("render.New() error = %v", err)
+	}
+
+	msgs, err := digestMessages(r, render.DefaultLang, "header", notices)
+	if err != nil {
+		t.Fatalf("digestMessages() error = %v", err)
+	}
+
+	if len(msgs) < 2 {
+		t.Fatalf("digestMessages() = %d messages, want several", len(msgs))
+	}
+
+	if !strings.HasPrefix(msgs[0], "header\n\n<b>— Книга a —</b>\n") {
+		t.Errorf("digestMessages() first message starts with %q", msgs[0][:40])
+	}
+
+	groups := 0
+	for _, m := range msgs {
+		if render.Len(m) > render.MaxMessageLen {
+			t.Errorf("digestMessages() message length %d exceeds limit", render.Len(m))
+		}
+
+		groups += strings.Count(m, "— Книга ")
+	}
+
+	if groups != 2 {
+		t.Errorf("digestMessages() groups = %d, want 2", groups)
+	}
+}
+
+var update = flag.Bool("update", false, "update golden files")
+
+// goldenSets — каталоги эталонных сводок для каждого языка шаблонов.
+var goldenSets = []struct {
+	lang string
+	dir  string
+}{
+	{lang: render.DefaultLang, dir: "testdata"},
+	{lang: "en", dir: filepath.Join("testdata", "en")},
+}
+
+func Test_digestTemplates(t *testing.T) {
+	r, err := render.New("")
+	if err != nil {
+		t.Fatalf("render.New() error = %v", err)
+	}
+
+	notices := []*sqlapi.NoticeRow{
+		{LinkID: "a", Title: "Книга <b>&</b>", Text: "В книге новые главы: 1."},
+		{LinkID: "a", Title: "Книга <b>&</b>", Text: "Цена книги изменилась."},
+		{LinkID: "b", Link: "https://author.today/work/2?a=1&b=2", Text: "В книге изменилась аннотация."},
+	}
+
+	for _, set := range goldenSets {
+		for _, delivery := range []string{events.DeliveryDaily, events.DeliveryWeekly, events.DeliveryInstant} {
+			t.Run(set.lang+"/"+delivery, func(t *testing.T) {
+				header, err := digestHeader(r, set.lang, delivery, len(notices))
+				if err != nil {
+					t.Fatalf("digestHeader() error = %v", err)
+				}
+
+				msgs, err := digestMessages(r, set.lang, header, notices)
+				if err != nil {
+					t.Fatalf("digestMessages() error = %v", err)
+				}
+
+				got := strings.Join(msgs, "\n---\n")
+				golden := filepath.Join(set.dir, "digest_"+delivery+".golden")
+
+				if *update {
+					if err := os.WriteFile(golden, []byte(got), 0o644); err != nil {
+						t.Fatalf("write golden file: %v", err)
+					}
+				}
+
+				want, err := os.ReadFile(golden)
+				if err != nil {
+					t.Fatalf("read golden file: %v", err)
+				}
+
+				if got != string(want) {
+					t.Errorf("digest = %q, want %q", got, want)
+				}
+			})
+		}
+	}
+}
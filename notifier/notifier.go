@@ -0,0 +1,158 @@
+package notifier
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/EfimoffN/authorBot/events"
+	"github.com/EfimoffN/authorBot/lib/e"
+	"github.com/EfimoffN/authorBot/lib/logger"
+	"github.com/EfimoffN/authorBot/metrics"
+	"github.com/EfimoffN/authorBot/render"
+	"github.com/EfimoffN/authorBot/sqlapi"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+const flushInterval = time.Minute
+
+// Notifier доставляет оповещения подписчикам: сразу или сводкой,
+// в зависимости от выбранного пользователем способа доставки.
+type Notifier struct {
+	BotAPI   *tgbotapi.BotAPI
+	Event    events.IEvent
+	Renderer *render.Renderer
+	Log      *slog.Logger
+	CTX      context.Context
+}
+
+func NewNotifier(botAPI *tgbotapi.BotAPI, event events.IEvent, renderer *render.Renderer, log *slog.Logger, ctx context.Context) *Notifier {
+	return &Notifier{
+		BotAPI:   botAPI,
+		Event:    event,
+		Renderer: renderer,
+		Log:      log,
+		CTX:      ctx,
+	}
+}
+
+// Notify отправляет оповещение подписчику или откладывает его до сводки
+// либо до конца тихих часов.
+func (n *Notifier) Notify(sub *sqlapi.SubscriberRow, linkID, msg string) {
+	instant := sub.Delivery == "" || sub.Delivery == events.DeliveryInstant
+	log := n.Log.With(logger.KeyUserID, sub.UserID, logger.KeyChatID, sub.ChatID, logger.KeyLinkID, linkID)
+
+	if instant && !isQuiet(sub.QuietStart, sub.QuietEnd, sub.Timezone, time.Now()) {
+		n.send(log, sub.ChatID, msg)
+		return
+	}
+
+	if err := n.Event.AddNotice(sub.UserID, sub.ChatID, linkID, msg); err != nil {
+		log.Error("add notice", logger.Err(err))
+		n.send(log, sub.ChatID, msg)
+
+		return
+	}
+
+	metrics.Notifications.WithLabelValues(metrics.ResultDeferred).Inc()
+}
+
+// Start раз в минуту рассылает сводки, время которых подошло, и
+// оповещения, отложенные на тихие часы, пока не будет отменен контекст.
+func (n *Notifier) Start() error {
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-n.CTX.Done():
+			return nil
+		case now := <-ticker.C:
+			n.flushAll(now)
+		}
+	}
+}
+
+func (n *Notifier) flushAll(now time.Time) {
+	userRows, err := n.Event.GetPendingUsers()
+	if err != nil {
+		n.Log.Error("get pending users", logger.Err(err))
+		return
+	}
+
+	metrics.QueueDepth.WithLabelValues(metrics.QueuePending).Set(float64(len(userRows)))
+
+	for _, u := range userRows {
+		if isQuiet(u.QuietStart, u.QuietEnd, u.Timezone, now) || !digestDue(u, now) {
+			continue
+		}
+
+		if err := n.flush(u, now); err != nil {
+			n.Log.Error("flush digest", logger.KeyUserID, u.UserID, logger.Err(err))
+		}
+	}
+}
+
+// flush отправляет пользователю все отложенные оповещения одной сводкой.
+func (n *Notifier) flush(user *sqlapi.UserRow, now time.Time) error {
+	noticeRows, err := n.Event.GetNotices(user.UserID)
+	if err != nil {
+		return e.Wrap("get notices failed with an error: ", err)
+	}
+
+	if len(noticeRows) == 0 {
+		return nil
+	}
+
+	last := noticeRows[0].Created
+	for _, r := range noticeRows {
+		if r.Created.After(last) {
+			last = r.Created
+		}
+	}
+
+	header, err := digestHeader(n.Renderer, user.Lang, user.Delivery, len(noticeRows))
+	if err != nil {
+		return e.Wrap("render digest header failed with an error: ", err)
+	}
+
+	messages, err := digestMessages(n.Renderer, user.Lang, header, noticeRows)
+	if err != nil {
+		return e.Wrap("build digest failed with an error: ", err)
+	}
+
+	log := n.Log.With(logger.KeyUserID, user.UserID, logger.KeyChatID, user.ChatID)
+
+	for _, msg := range messages {
+		n.send(log, user.ChatID, msg)
+	}
+
+	if err := n.Event.RemoveNotices(user.UserID, last); err != nil {
+		return e.Wrap("remove notices failed with an error: ", err)
+	}
+
+	if err := n.Event.SetLastDigest(user.UserID, now); err != nil {
+		return e.Wrap("set last digest failed with an error: ", err)
+	}
+
+	return nil
+}
+
+// send отправляет оповещение в разметке HTML, при необходимости
+// несколькими сообщениями.
+func (n *Notifier) send(log *slog.Logger, chatID int64, msg string) {
+	for _, part := range render.Split(msg, render.MaxMessageLen) {
+		m := tgbotapi.NewMessage(chatID, part)
+		m.ParseMode = tgbotapi.ModeHTML
+
+		_, err := n.BotAPI.Send(m)
+		if err != nil {
+			log.Error("sending the message", logger.Err(err))
+			metrics.Notifications.WithLabelValues(metrics.ResultFailed).Inc()
+
+			continue
+		}
+
+		metrics.Notifications.WithLabelValues(metrics.ResultSent).Inc()
+	}
+}
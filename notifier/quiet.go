@@ -0,0 +1,25 @@
+package notifier
+
+import (
+	"time"
+
+	"github.com/EfimoffN/authorBot/lib/tz"
+)
+
+// isQuiet сообщает, приходится ли момент now на тихие часы пользователя.
+// Границы заданы в минутах от полуночи в его часовом поясе; окно может
+// переходить через полночь, а равные границы означают, что тихих часов нет.
+func isQuiet(start, end int, timezone string, now time.Time) bool {
+	if start == end {
+		return false
+	}
+
+	t := now.In(tz.Location(timezone))
+	m := t.Hour()*60 + t.Minute()
+
+	if start < end {
+		return m >= start && m < end
+	}
+
+	return m >= start || m < end
+}
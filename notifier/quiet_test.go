@@ -0,0 +1,71 @@
+package notifier
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_isQuiet(t *testing.T) {
+	tests := []struct {
+		name       string
+		start, end int
+		timezone   string
+		now        time.Time
+		want       bool
+	}{
+		{
+			name:     "disabled",
+			timezone: "UTC",
+			now:      time.Date(2023, 5, 10, 23, 30, 0, 0, time.UTC),
+			want:     false,
+		},
+		{
+			name:     "over midnight, late evening",
+			start:    23 * 60,
+			end:      8 * 60,
+			timezone: "UTC",
+			now:      time.Date(2023, 5, 10, 23, 30, 0, 0, time.UTC),
+			want:     true,
+		},
+		{
+			name:     "over midnight, early morning",
+			start:    23 * 60,
+			end:      8 * 60,
+			timezone: "UTC",
+			now:      time.Date(2023, 5, 10, 7, 59, 0, 0, time.UTC),
+			want:     true,
+		},
+		{
+			name:     "over midnight, window ended",
+			start:    23 * 60,
+			end:      8 * 60,
+			timezone: "UTC",
+			now:      time.Date(2023, 5, 10, 8, 0, 0, 0, time.UTC),
+			want:     false,
+		},
+		{
+			name:     "daytime window",
+			start:    13 * 60,
+			end:      14 * 60,
+			timezone: "UTC",
+			now:      time.Date(2023, 5, 10, 13, 15, 0, 0, time.UTC),
+			want:     true,
+		},
+		{
+			name:     "user time zone",
+			start:    23 * 60,
+			end:      8 * 60,
+			timezone: "Europe/Moscow",
+			now:      time.Date(2023, 5, 10, 21, 0, 0, 0, time.UTC),
+			want:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isQuiet(tt.start, tt.end, tt.timezone, tt.now); got != tt.want {
+				t.Errorf("isQuiet() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
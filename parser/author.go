@@ -0,0 +1,209 @@
+package parser
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/EfimoffN/authorBot/lib/e"
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Селекторы страницы произведений автора на author.today.
+const (
+	authorNameSelector  = ".profile-name"
+	workSelector        = ".book-row"
+	workTitleSelector   = ".book-title a"
+	workSeriesSelector  = "a[href^='/work/series/']"
+	workSeriesTitleAttr = "title"
+	nextPageSelector    = ".pagination li.next a"
+)
+
+// maxAuthorPages ограничивает число страниц списка произведений, которые
+// загружаются за одну проверку.
+const maxAuthorPages = 50
+
+const baseURL = "https://author.today"
+
+var (
+	authorPathRegexp = regexp.MustCompile(`^/u/([^/]+)(/works)?/?$`)
+	workIDRegexp     = regexp.MustCompile(`/work/(\d+)`)
+	seriesIDRegexp   = regexp.MustCompile(`/work/series/(\d+)`)
+	seriesPathRegexp = regexp.MustCompile(`^/work/series/(\d+)/?$`)
+	workPathRegexp   = regexp.MustCompile(`^/work/(\d+)/?$`)
+)
+
+// Author ...
+type Author struct {
+	Name  string
+	Works []Work
+}
+
+// Work ...
+type Work struct {
+	ID          string
+	Title       string
+	SeriesID    string
+	SeriesTitle string
+}
+
+// WorkURL возвращает ссылку на произведение по его идентификатору.
+func WorkURL(id string) string {
+	return baseURL + "/work/" + id
+}
+
+// GetAuthor загружает все страницы списка произведений автора.
+func (p *Parser) GetAuthor(ctx context.Context, link string) (*Author, error) {
+	login := AuthorLogin(link)
+	if login == "" {
+		return nil, e.Wrap(link, ErrNotAuthor)
+	}
+
+	var author *Author
+
+	next := baseURL + "/u/" + login + "/works"
+	for page := 0; next != "" && page < maxAuthorPages; page++ {
+		pageAuthor, pageNext, err := p.getAuthorPage(ctx, next)
+		if err != nil {
+			return nil, err
+		}
+
+		if author == nil {
+			author = pageAuthor
+		} else {
+			author.Works = append(author.Works, pageAuthor.Works...)
+		}
+
+		next = pageNext
+	}
+
+	if author.Name == "" {
+		author.Name = login
+	}
+
+	return author, nil
+}
+
+// getAuthorPage загружает одну страницу списка произведений и возвращает
+// ее вместе со ссылкой на следующую страницу.
+func (p *Parser) getAuthorPage(ctx context.Context, link string) (*Author, string, error) {
+	body, err := p.get(ctx, link)
+	if err != nil {
+		return nil, "", e.Wrap("get author page failed with an error: ", err)
+	}
+	defer body.Close()
+
+	doc, err := goquery.NewDocumentFromReader(body)
+	if err != nil {
+		return nil, "", e.Wrap("read document failed with an error: ", err)
+	}
+
+	return parseAuthor(doc), nextPage(doc, link), nil
+}
+
+// ParseAuthor разбирает одну страницу списка произведений автора.
+func ParseAuthor(r io.Reader) (*Author, error) {
+	doc, err := goquery.NewDocumentFromReader(r)
+	if err != nil {
+		return nil, e.Wrap("read document failed with an error: ", err)
+	}
+
+	return parseAuthor(doc), nil
+}
+
+func parseAuthor(doc *goquery.Document) *Author {
+	author := &Author{
+		Name: strings.TrimSpace(doc.Find(authorNameSelector).First().Text()),
+	}
+
+	doc.Find(workSelector).Each(func(_ int, s *goquery.Selection) {
+		title := s.Find(workTitleSelector).First()
+		href, _ := title.Attr("href")
+
+		id := submatch(workIDRegexp, href)
+		if id == "" {
+			return
+		}
+
+		work := Work{
+			ID:    id,
+			Title: strings.TrimSpace(title.Text()),
+		}
+
+		series := s.Find(workSeriesSelector).First()
+		if href, ok := series.Attr("href"); ok {
+			work.SeriesID = submatch(seriesIDRegexp, href)
+			work.SeriesTitle = strings.TrimSpace(series.Text())
+
+			if t, ok := series.Attr(workSeriesTitleAttr); ok && work.SeriesTitle == "" {
+				work.SeriesTitle = strings.TrimSpace(t)
+			}
+		}
+
+		author.Works = append(author.Works, work)
+	})
+
+	return author
+}
+
+// nextPage возвращает абсолютную ссылку на следующую страницу списка или
+// пустую строку на последней странице.
+func nextPage(doc *goquery.Document, link string) string {
+	href, ok := doc.Find(nextPageSelector).First().Attr("href")
+	if !ok || href == "" {
+		return ""
+	}
+
+	base, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+
+	next, err := base.Parse(href)
+	if err != nil || next.String() == link {
+		return ""
+	}
+
+	return next.String()
+}
+
+// AuthorLogin возвращает логин автора из ссылки на его профиль.
+func AuthorLogin(link string) string {
+	u, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+
+	return submatch(authorPathRegexp, u.Path)
+}
+
+// SeriesID возвращает идентификатор цикла из ссылки на него.
+func SeriesID(link string) string {
+	u, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+
+	return submatch(seriesPathRegexp, u.Path)
+}
+
+// WorkID возвращает идентификатор произведения из ссылки на него.
+func WorkID(link string) string {
+	u, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+
+	return submatch(workPathRegexp, u.Path)
+}
+
+func submatch(re *regexp.Regexp, text string) string {
+	m := re.FindStringSubmatch(text)
+	if len(m) < 2 {
+		return ""
+	}
+
+	return m[1]
+}
@@ -0,0 +1,195 @@
+package parser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/EfimoffN/authorBot/lib/e"
+	"github.com/EfimoffN/authorBot/metrics"
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Селекторы страницы книги на author.today.
+const (
+	titleSelector      = "h1.book-title"
+	chapterSelector    = "ul.table-of-content > li"
+	priceSelector      = ".book-action-panel .price"
+	oldPriceSelector   = ".book-action-panel .old-price"
+	discountSelector   = ".book-action-panel .discount"
+	freeSelector       = ".book-action-panel .free"
+	statusSelector     = ".book-meta-panel .book-status"
+	annotationSelector = ".annotation .rich-content"
+)
+
+// Статусы книги.
+const (
+	StatusInProgress = "in_progress"
+	StatusCompleted  = "completed"
+	StatusFrozen     = "frozen"
+	StatusHidden     = "hidden"
+)
+
+const (
+	userAgent      = "Mozilla/5.0 (compatible; authorBot/1.0)"
+	requestTimeout = 30 * time.Second
+)
+
+var (
+	ErrPageNotFound  = errors.New("page not found")
+	ErrPageForbidden = errors.New("page access forbidden")
+	ErrNoTitle       = errors.New("title not found")
+	ErrNotAuthor     = errors.New("link is not an author profile")
+	ErrNotSeries     = errors.New("link is not a series")
+)
+
+var digitsRegexp = regexp.MustCompile(`\d+`)
+
+// Book ...
+type Book struct {
+	Title    string
+	Chapters int
+	Price    int
+	Discount int
+	Free     bool
+	// HasPrice — на странице найдена цена или отметка о бесплатной книге.
+	// Если нет, цену нельзя сравнивать с прежней: скорее всего, поменялась
+	// верстка.
+	HasPrice   bool
+	Status     string
+	Annotation string
+}
+
+type Parser struct {
+	client *http.Client
+}
+
+func NewParser() *Parser {
+	return &Parser{
+		client: &http.Client{Timeout: requestTimeout},
+	}
+}
+
+func (p *Parser) GetBook(ctx context.Context, link string) (*Book, error) {
+	body, err := p.get(ctx, link)
+	if err != nil {
+		return nil, e.Wrap("get book page failed with an error: ", err)
+	}
+	defer body.Close()
+
+	book, err := ParseBook(body)
+	if err != nil {
+		return nil, e.Wrap("parse book page failed with an error: ", err)
+	}
+
+	return book, nil
+}
+
+func (p *Parser) get(ctx context.Context, link string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, link, nil)
+	if err != nil {
+		return nil, e.Wrap("new request failed with an error: ", err)
+	}
+
+	req.Header.Set("User-Agent", userAgent)
+
+	resp, err := p.client.Do(req)
+	metrics.ObserveFetch(metrics.ClientPage, resp, err)
+
+	if err != nil {
+		return nil, e.Wrap("do request failed with an error: ", err)
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, e.Wrap(link, ErrPageNotFound)
+	}
+
+	if resp.StatusCode == http.StatusForbidden {
+		resp.Body.Close()
+		return nil, e.Wrap(link, ErrPageForbidden)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("unexpected status code %d for %s", resp.StatusCode, link)
+	}
+
+	return resp.Body, nil
+}
+
+func ParseBook(r io.Reader) (*Book, error) {
+	doc, err := goquery.NewDocumentFromReader(r)
+	if err != nil {
+		return nil, e.Wrap("read document failed with an error: ", err)
+	}
+
+	book := &Book{
+		Title:    strings.TrimSpace(doc.Find(titleSelector).First().Text()),
+		Chapters: doc.Find(chapterSelector).Length(),
+		Status:   parseStatus(doc.Find(statusSelector).First().Text()),
+		// Пробелы внутри аннотации схлопываются, чтобы правка верстки не
+		// выглядела как правка текста.
+		Annotation: strings.Join(strings.Fields(doc.Find(annotationSelector).First().Text()), " "),
+	}
+
+	if book.Title == "" {
+		return nil, ErrNoTitle
+	}
+
+	book.Price = parseNumber(doc.Find(priceSelector).First().Text())
+	book.Discount = parseNumber(doc.Find(discountSelector).First().Text())
+
+	if book.Discount == 0 {
+		oldPrice := parseNumber(doc.Find(oldPriceSelector).First().Text())
+		if oldPrice > book.Price && book.Price > 0 {
+			book.Discount = (oldPrice - book.Price) * 100 / oldPrice
+		}
+	}
+
+	book.Free = doc.Find(freeSelector).Length() > 0
+	book.HasPrice = book.Free || book.Price > 0
+
+	if !book.HasPrice || book.Free {
+		book.Price = 0
+		book.Discount = 0
+	}
+
+	return book, nil
+}
+
+// IsHidden сообщает, что страница книги недоступна: книга удалена,
+// скрыта или перенесена в черновики.
+func IsHidden(err error) bool {
+	return errors.Is(err, ErrPageNotFound) || errors.Is(err, ErrPageForbidden)
+}
+
+func parseStatus(text string) string {
+	text = strings.ToLower(text)
+
+	switch {
+	case strings.Contains(text, "заморож"):
+		return StatusFrozen
+	case strings.Contains(text, "весь текст"), strings.Contains(text, "заверш"):
+		return StatusCompleted
+	case strings.Contains(text, "черновик"), strings.Contains(text, "скрыт"):
+		return StatusHidden
+	default:
+		return StatusInProgress
+	}
+}
+
+func parseNumber(text string) int {
+	n, err := strconv.Atoi(strings.Join(digitsRegexp.FindAllString(text, -1), ""))
+	if err != nil {
+		return 0
+	}
+
+	return n
+}
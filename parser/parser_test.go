@@ -0,0 +1,194 @@
+package parser
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fileTransport отдает вместо страниц author.today файлы из testdata.
+type fileTransport map[string]string
+
+func (f fileTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	file, ok := f[r.URL.RequestURI()]
+	if !ok {
+		return &http.Response{StatusCode: http.StatusNotFound, Body: http.NoBody, Request: r}, nil
+	}
+
+	body, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: body, Request: r}, nil
+}
+
+func Test_ParseBook(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		want    *Book
+		wantErr bool
+	}{
+		{
+			name: "paid book with discount",
+			file: "testdata/book.html",
+			want: &Book{
+				Title:      "Тестовая книга",
+				Chapters:   3,
+				Price:      150,
+				Discount:   25,
+				Free:       false,
+				HasPrice:   true,
+				Status:     StatusInProgress,
+				Annotation: "Первая строка аннотации. Вторая строка.",
+			},
+			wantErr: false,
+		},
+		{
+			name: "free book",
+			file: "testdata/book_free.html",
+			want: &Book{
+				Title:    "Бесплатная книга",
+				Chapters: 1,
+				Free:     true,
+				HasPrice: true,
+				Status:   StatusCompleted,
+			},
+			wantErr: false,
+		},
+		{
+			name: "price not found",
+			file: "testdata/book_noprice.html",
+			want: &Book{
+				Title:    "Книга без цены",
+				Chapters: 1,
+				Status:   StatusInProgress,
+			},
+			wantErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, err := os.Open(tt.file)
+			if err != nil {
+				t.Fatalf("open test data: %s", err)
+			}
+			defer file.Close()
+
+			got, err := ParseBook(file)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseBook() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseBook() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_ParseBookNoTitle(t *testing.T) {
+	_, err := ParseBook(strings.NewReader("<html><body></body></html>"))
+	if err == nil {
+		t.Errorf("ParseBook() error = nil, want %v", ErrNoTitle)
+	}
+}
+
+func Test_parseStatus(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{text: "В процессе", want: StatusInProgress},
+		{text: "Весь текст", want: StatusCompleted},
+		{text: "Заморожен", want: StatusFrozen},
+		{text: "Черновик", want: StatusHidden},
+		{text: "", want: StatusInProgress},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got := parseStatus(tt.text); got != tt.want {
+				t.Errorf("parseStatus() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_ParseAuthor(t *testing.T) {
+	file, err := os.Open("testdata/author.html")
+	if err != nil {
+		t.Fatalf("open test data: %s", err)
+	}
+	defer file.Close()
+
+	want := &Author{
+		Name: "Иван Автор",
+		Works: []Work{
+			{ID: "101", Title: "Первая книга", SeriesID: "7", SeriesTitle: "Большой цикл"},
+			{ID: "102", Title: "Вторая книга", SeriesID: "7", SeriesTitle: "Большой цикл"},
+			{ID: "200", Title: "Отдельный рассказ"},
+		},
+	}
+
+	got, err := ParseAuthor(file)
+	if err != nil {
+		t.Fatalf("ParseAuthor() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseAuthor() = %+v, want %+v", got, want)
+	}
+}
+
+func Test_GetAuthor(t *testing.T) {
+	p := &Parser{client: &http.Client{Transport: fileTransport{
+		"/u/login/works":        "testdata/author.html",
+		"/u/login/works?page=2": "testdata/author_page2.html",
+	}}}
+
+	got, err := p.GetAuthor(context.Background(), "https://author.today/u/login")
+	if err != nil {
+		t.Fatalf("GetAuthor() error = %v", err)
+	}
+
+	ids := []string{}
+	for _, w := range got.Works {
+		ids = append(ids, w.ID)
+	}
+
+	if want := []string{"101", "102", "200", "50"}; got.Name != "Иван Автор" || !reflect.DeepEqual(ids, want) {
+		t.Errorf("GetAuthor() = %q %v, want %q %v", got.Name, ids, "Иван Автор", want)
+	}
+}
+
+func Test_ParseSeries(t *testing.T) {
+	file, err := os.Open("testdata/series.html")
+	if err != nil {
+		t.Fatalf("open test data: %s", err)
+	}
+	defer file.Close()
+
+	want := &Series{
+		Title: "Большой цикл",
+		Books: []SeriesBook{
+			{ID: "101", Title: "Первая книга", Position: 1, Status: StatusCompleted},
+			{ID: "102", Title: "Вторая книга", Position: 2, Status: StatusInProgress},
+		},
+	}
+
+	got, err := ParseSeries(file)
+	if err != nil {
+		t.Fatalf("ParseSeries() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseSeries() = %+v, want %+v", got, want)
+	}
+}
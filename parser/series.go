@@ -0,0 +1,85 @@
+package parser
+
+import (
+	"context"
+	"io"
+	"strings"
+
+	"github.com/EfimoffN/authorBot/lib/e"
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Селекторы страницы цикла на author.today.
+const (
+	seriesTitleSelector  = "h1.series-title"
+	seriesStatusSelector = ".book-status"
+)
+
+// Series ...
+type Series struct {
+	Title string
+	Books []SeriesBook
+}
+
+// SeriesBook ...
+type SeriesBook struct {
+	ID       string
+	Title    string
+	Position int
+	Status   string
+}
+
+func (p *Parser) GetSeries(ctx context.Context, link string) (*Series, error) {
+	if SeriesID(link) == "" {
+		return nil, e.Wrap(link, ErrNotSeries)
+	}
+
+	body, err := p.get(ctx, link)
+	if err != nil {
+		return nil, e.Wrap("get series page failed with an error: ", err)
+	}
+	defer body.Close()
+
+	series, err := ParseSeries(body)
+	if err != nil {
+		return nil, e.Wrap("parse series page failed with an error: ", err)
+	}
+
+	return series, nil
+}
+
+// ParseSeries разбирает страницу цикла. Книги возвращаются в порядке
+// следования в цикле, нумерация позиций начинается с единицы.
+func ParseSeries(r io.Reader) (*Series, error) {
+	doc, err := goquery.NewDocumentFromReader(r)
+	if err != nil {
+		return nil, e.Wrap("read document failed with an error: ", err)
+	}
+
+	series := &Series{
+		Title: strings.TrimSpace(doc.Find(seriesTitleSelector).First().Text()),
+	}
+
+	if series.Title == "" {
+		return nil, ErrNoTitle
+	}
+
+	doc.Find(workSelector).Each(func(_ int, s *goquery.Selection) {
+		title := s.Find(workTitleSelector).First()
+		href, _ := title.Attr("href")
+
+		id := submatch(workIDRegexp, href)
+		if id == "" {
+			return
+		}
+
+		series.Books = append(series.Books, SeriesBook{
+			ID:       id,
+			Title:    strings.TrimSpace(title.Text()),
+			Position: len(series.Books) + 1,
+			Status:   parseStatus(s.Find(seriesStatusSelector).First().Text()),
+		})
+	})
+
+	return series, nil
+}
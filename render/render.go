@@ -0,0 +1,135 @@
+package render
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"html/template"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/EfimoffN/authorBot/lib/e"
+)
+
+// Ext — расширение файлов шаблонов. Имя шаблона совпадает с именем файла.
+const Ext = ".tmpl"
+
+// DefaultLang — язык шаблонов в корне каталога. Шаблоны на других языках
+// лежат в подкаталогах с именем языка, например en/chapters.tmpl.
+const DefaultLang = "ru"
+
+// Langs — языки, для которых есть встроенные шаблоны, кроме DefaultLang.
+var Langs = []string{"en"}
+
+//go:embed templates/*.tmpl templates/en/*.tmpl
+var defaults embed.FS
+
+// Renderer формирует тексты оповещений в разметке HTML Telegram по
+// шаблонам html/template на языке подписчика. Все подставляемые значения
+// экранируются автоматически, даже если шаблон забыл функцию html.
+type Renderer struct {
+	mu   sync.RWMutex
+	tmpl map[string]*template.Template
+}
+
+// New загружает встроенные шаблоны и заменяет их одноименными файлами
+// *.tmpl из каталога dir, если он указан. Шаблоны на другом языке
+// заменяются файлами из подкаталога с именем языка.
+func New(dir string) (*Renderer, error) {
+	tmpl, err := parse(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Renderer{tmpl: tmpl}, nil
+}
+
+// Reload заново загружает шаблоны из каталога dir. Если шаблоны не
+// разбираются, остаются прежние.
+func (r *Renderer) Reload(dir string) error {
+	tmpl, err := parse(dir)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.tmpl = tmpl
+
+	return nil
+}
+
+func parse(dir string) (map[string]*template.Template, error) {
+	sets := map[string]*template.Template{}
+	found := 0
+
+	for _, lang := range append([]string{DefaultLang}, Langs...) {
+		tmpl, err := template.ParseFS(defaults, path.Join("templates", langDir(lang), "*"+Ext))
+		if err != nil {
+			return nil, e.Wrap("parse default templates failed with an error: ", err)
+		}
+
+		sets[lang] = tmpl
+
+		if dir == "" {
+			continue
+		}
+
+		files, err := filepath.Glob(filepath.Join(dir, langDir(lang), "*"+Ext))
+		if err != nil {
+			return nil, e.Wrap("list templates failed with an error: ", err)
+		}
+
+		if len(files) == 0 {
+			continue
+		}
+
+		found += len(files)
+
+		if _, err := tmpl.ParseFiles(files...); err != nil {
+			return nil, e.Wrap("parse templates failed with an error: ", err)
+		}
+	}
+
+	if dir != "" && found == 0 {
+		return nil, fmt.Errorf("no templates in %s", dir)
+	}
+
+	return sets, nil
+}
+
+// langDir возвращает подкаталог шаблонов языка lang.
+func langDir(lang string) string {
+	if lang == DefaultLang {
+		return ""
+	}
+
+	return lang
+}
+
+// Execute заполняет шаблон name на языке lang данными data. Для языка без
+// шаблонов используется DefaultLang.
+func (r *Renderer) Execute(lang, name string, data interface{}) (string, error) {
+	r.mu.RLock()
+	tmpl, ok := r.tmpl[lang]
+	if !ok {
+		tmpl = r.tmpl[DefaultLang]
+	}
+	r.mu.RUnlock()
+
+	buf := &bytes.Buffer{}
+
+	if err := tmpl.ExecuteTemplate(buf, name+Ext, data); err != nil {
+		return "", e.Wrap("execute template failed with an error: ", err)
+	}
+
+	return strings.TrimRight(buf.String(), "\n"), nil
+}
+
+// Escape экранирует текст для разметки HTML Telegram.
+func Escape(text string) string {
+	return template.HTMLEscapeString(text)
+}
@@ -0,0 +1,273 @@
+package render
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func Test_New(t *testing.T) {
+	dir := t.TempDir()
+
+	override := "Глава в «{{html .Title}}»!\n"
+	if err := os.WriteFile(filepath.Join(dir, "chapters"+Ext), []byte(override), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := New(dir)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	got, err := r.Execute(DefaultLang, "chapters", struct{ Title string }{"<Тени>"})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	if want := "Глава в «&lt;Тени&gt;»!"; got != want {
+		t.Errorf("Execute() = %q, want %q", got, want)
+	}
+
+	got, err = r.Execute(DefaultLang, "digest_group", struct{ Title string }{"Книга"})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	if want := "<b>— Книга —</b>"; got != want {
+		t.Errorf("Execute() default = %q, want %q", got, want)
+	}
+
+	got, err = r.Execute("en", "digest_header", struct {
+		Delivery string
+		Count    int
+	}{"daily", 2})
+	if err != nil {
+		t.Fatalf("Execute(en) error = %v", err)
+	}
+
+	if want := "Daily summary of changes, notifications: 2."; got != want {
+		t.Errorf("Execute(en) = %q, want %q", got, want)
+	}
+}
+
+func Test_NewLangDir(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "en"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "en", "chapters"+Ext), []byte("Chapters in {{html .Title}}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := New(dir)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	tests := []struct {
+		lang string
+		want string
+	}{
+		{lang: "en", want: "Chapters in &lt;Тени&gt;"},
+		{lang: DefaultLang, want: "В книге «<b>&lt;Тени&gt;</b>» новые главы: 0."},
+		{lang: "de", want: "В книге «<b>&lt;Тени&gt;</b>» новые главы: 0."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.lang, func(t *testing.T) {
+			got, err := r.Execute(tt.lang, "chapters", struct {
+				Title string
+				Count int
+				Time  string
+				Link  string
+			}{Title: "<Тени>"})
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("Execute() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_ExecuteEscapes(t *testing.T) {
+	dir := t.TempDir()
+
+	override := "<b>{{.Title}}</b>\n{{.Link}}\n"
+	if err := os.WriteFile(filepath.Join(dir, "annotation"+Ext), []byte(override), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := New(dir)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	got, err := r.Execute(DefaultLang, "annotation", struct{ Title, Link string }{"<i>Тени</i> & свет", "https://a.b/?x=1&y=2"})
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	if want := "<b>&lt;i&gt;Тени&lt;/i&gt; &amp; свет</b>\nhttps://a.b/?x=1&amp;y=2"; got != want {
+		t.Errorf("Execute() = %q, want %q", got, want)
+	}
+}
+
+// Test_LangsCoverage проверяет, что у каждого встроенного шаблона есть
+// перевод на каждый язык и нет шаблонов без оригинала.
+func Test_LangsCoverage(t *testing.T) {
+	names := func(lang string) []string {
+		files, err := fs.Glob(defaults, path.Join("templates", langDir(lang), "*"+Ext))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for i, f := range files {
+			files[i] = path.Base(f)
+		}
+
+		sort.Strings(files)
+
+		return files
+	}
+
+	want := names(DefaultLang)
+	if len(want) == 0 {
+		t.Fatal("no default templates")
+	}
+
+	for _, lang := range Langs {
+		t.Run(lang, func(t *testing.T) {
+			if got := names(lang); strings.Join(got, ",") != strings.Join(want, ",") {
+				t.Errorf("templates %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func Test_Reload(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "chapters"+Ext)
+
+	if err := os.WriteFile(path, []byte("old"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := New(dir)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	steps := []struct {
+		content string
+		wantErr bool
+		want    string
+	}{
+		{content: "new", want: "new"},
+		{content: "{{if .Title}", wantErr: true, want: "new"},
+	}
+
+	for _, s := range steps {
+		if err := os.WriteFile(path, []byte(s.content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := r.Reload(dir); (err != nil) != s.wantErr {
+			t.Fatalf("Reload(%q) error = %v, wantErr %v", s.content, err, s.wantErr)
+		}
+
+		got, err := r.Execute(DefaultLang, "chapters", nil)
+		if err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+
+		if got != s.want {
+			t.Errorf("after Reload(%q) Execute() = %q, want %q", s.content, got, s.want)
+		}
+	}
+}
+
+func Test_NewErrors(t *testing.T) {
+	broken := t.TempDir()
+	if err := os.WriteFile(filepath.Join(broken, "chapters"+Ext), []byte("{{if .Title}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		dir  string
+	}{
+		{name: "empty dir", dir: t.TempDir()},
+		{name: "broken template", dir: broken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(tt.dir); err == nil {
+				t.Errorf("New() error = nil, want error")
+			}
+		})
+	}
+}
+
+func Test_Split(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		limit int
+		want  []string
+	}{
+		{
+			name:  "fits",
+			text:  "<b>книга</b> &amp; том",
+			limit: 11,
+			want:  []string{"<b>книга</b> &amp; том"},
+		},
+		{
+			name:  "by lines",
+			text:  "первая строка\nвторая",
+			limit: 15,
+			want:  []string{"первая строка", "вторая"},
+		},
+		{
+			name:  "reopens tags",
+			text:  `<b>абв <a href="https://a.b/?x=1&amp;y=2">где</a></b>`,
+			limit: 5,
+			want:  []string{"<b>абв <a href=\"https://a.b/?x=1&amp;y=2\">г</a></b>", "<b><a href=\"https://a.b/?x=1&amp;y=2\">де</a></b>"},
+		},
+		{
+			name:  "entity is one character",
+			text:  "&lt;&lt;&lt;",
+			limit: 2,
+			want:  []string{"&lt;&lt;", "&lt;"},
+		},
+		{
+			name:  "surrogate pairs",
+			text:  "😀😀😀",
+			limit: 4,
+			want:  []string{"😀😀", "😀"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Split(tt.text, tt.limit)
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("Split() = %q, want %q", got, tt.want)
+			}
+
+			for _, part := range got {
+				if Len(part) > tt.limit {
+					t.Errorf("Split() part %q is longer than %d", part, tt.limit)
+				}
+			}
+		})
+	}
+}
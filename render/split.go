@@ -0,0 +1,170 @@
+package render
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxMessageLen — ограничение Telegram на длину сообщения в символах
+// UTF-16 после разбора разметки.
+const MaxMessageLen = 4096
+
+// maxEntityLen — наибольшая длина мнемоники вида &amp; или &#12345;.
+const maxEntityLen = 10
+
+// token — тег, мнемоника или символ текста в разметке HTML.
+type token struct {
+	raw   string
+	width int
+}
+
+func (t token) isTag() bool {
+	return strings.HasPrefix(t.raw, "<")
+}
+
+func (t token) isClosing() bool {
+	return strings.HasPrefix(t.raw, "</")
+}
+
+// tagName возвращает имя тега: для <a href="..."> и </a> это a.
+func (t token) tagName() string {
+	name := strings.TrimPrefix(strings.TrimPrefix(t.raw, "<"), "/")
+	if i := strings.IndexAny(name, " \t\n>"); i >= 0 {
+		name = name[:i]
+	}
+
+	return strings.ToLower(name)
+}
+
+// Len возвращает длину текста в символах UTF-16 без учета тегов,
+// мнемоника считается одним символом.
+func Len(text string) int {
+	n := 0
+	for _, t := range tokenize(text) {
+		n += t.width
+	}
+
+	return n
+}
+
+// Split делит текст в разметке HTML на части не длиннее limit, по
+// возможности по переводам строк, а строку — по символам, не разрывая
+// теги и мнемоники. Теги, открытые на месте разреза, закрываются в конце
+// части и открываются заново в начале следующей.
+func Split(text string, limit int) []string {
+	tokens := tokenize(text)
+	parts := []string{}
+	open := []token{}
+
+	for start := 0; ; {
+		end, n := start, 0
+		stack := append([]token{}, open...)
+		lastNL, nlStack := -1, []token(nil)
+
+		for end < len(tokens) {
+			t := tokens[end]
+			if n+t.width > limit && end > start {
+				break
+			}
+
+			if t.raw == "\n" && end > start {
+				lastNL, nlStack = end, append([]token{}, stack...)
+			}
+
+			n += t.width
+			stack = track(stack, t)
+			end++
+		}
+
+		if end == len(tokens) {
+			return append(parts, join(open, tokens[start:end], stack))
+		}
+
+		next := end
+		if lastNL >= 0 {
+			end, next, stack = lastNL, lastNL+1, nlStack
+		} else if tokens[end].raw == "\n" {
+			next++
+		}
+
+		parts = append(parts, join(open, tokens[start:end], stack))
+		open, start = stack, next
+	}
+}
+
+// track обновляет стек открытых тегов.
+func track(stack []token, t token) []token {
+	if !t.isTag() {
+		return stack
+	}
+
+	if !t.isClosing() {
+		return append(stack, t)
+	}
+
+	for i := len(stack) - 1; i >= 0; i-- {
+		if stack[i].tagName() == t.tagName() {
+			return append(stack[:i:i], stack[i+1:]...)
+		}
+	}
+
+	return stack
+}
+
+// join собирает часть текста: заново открывает теги open, открытые до
+// нее, и закрывает теги closing, оставшиеся открытыми в ее конце.
+func join(open, tokens, closing []token) string {
+	b := strings.Builder{}
+
+	for _, t := range open {
+		b.WriteString(t.raw)
+	}
+
+	for _, t := range tokens {
+		b.WriteString(t.raw)
+	}
+
+	for i := len(closing) - 1; i >= 0; i-- {
+		b.WriteString("</" + closing[i].tagName() + ">")
+	}
+
+	return b.String()
+}
+
+func tokenize(text string) []token {
+	tokens := make([]token, 0, len(text))
+
+	for i := 0; i < len(text); {
+		switch text[i] {
+		case '<':
+			if j := strings.IndexByte(text[i:], '>'); j > 0 {
+				tokens = append(tokens, token{raw: text[i : i+j+1]})
+				i += j + 1
+
+				continue
+			}
+		case '&':
+			if j := strings.IndexByte(text[i:], ';'); j > 1 && j <= maxEntityLen {
+				tokens = append(tokens, token{raw: text[i : i+j+1], width: 1})
+				i += j + 1
+
+				continue
+			}
+		}
+
+		r, size := utf8.DecodeRuneInString(text[i:])
+		tokens = append(tokens, token{raw: text[i : i+size], width: runeLen(r)})
+		i += size
+	}
+
+	return tokens
+}
+
+// runeLen возвращает число символов UTF-16, которыми кодируется r.
+func runeLen(r rune) int {
+	if r >= 0x10000 {
+		return 2
+	}
+
+	return 1
+}
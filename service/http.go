@@ -0,0 +1,26 @@
+package service
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/EfimoffN/authorBot/lib/e"
+)
+
+const readHeaderTimeout = 10 * time.Second
+
+// ServeHTTP отдает служебные страницы бота, например метрики, на адресе
+// addr. Возвращается только с ошибкой.
+func ServeHTTP(addr string, handler http.Handler) error {
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+
+	if err := srv.ListenAndServe(); err != nil {
+		return e.Wrap("listen and serve failed with an error: ", err)
+	}
+
+	return nil
+}
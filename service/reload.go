@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/EfimoffN/authorBot/commands"
+	"github.com/EfimoffN/authorBot/config"
+	"github.com/EfimoffN/authorBot/lib/e"
+	"github.com/EfimoffN/authorBot/lib/logger"
+	"github.com/EfimoffN/authorBot/render"
+	"github.com/EfimoffN/authorBot/watcher"
+)
+
+// Reloader по SIGHUP перечитывает конфигурацию и применяет то, что можно
+// поменять на ходу: уровень журнала, интервал проверок, скорость рассылки,
+// администраторов и шаблоны. Поток обновлений Telegram не прерывается.
+type Reloader struct {
+	Path     string
+	Level    *slog.LevelVar
+	Commands *commands.Commands
+	Watcher  *watcher.Watcher
+	Renderer *render.Renderer
+	Log      *slog.Logger
+	// started — конфигурация, с которой запущен бот. С ней сравниваются
+	// настройки, требующие перезапуска.
+	started *config.ConfigApp
+}
+
+func NewReloader(path string, started *config.ConfigApp, level *slog.LevelVar, cmd *commands.Commands, wtc *watcher.Watcher, renderer *render.Renderer, log *slog.Logger) *Reloader {
+	return &Reloader{
+		Path:     path,
+		Level:    level,
+		Commands: cmd,
+		Watcher:  wtc,
+		Renderer: renderer,
+		Log:      log,
+		started:  started,
+	}
+}
+
+// Start ждет SIGHUP и перезагружает конфигурацию, пока не будет отменен
+// контекст.
+func (r *Reloader) Start(ctx context.Context) error {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	defer signal.Stop(signals)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-signals:
+			if err := r.Reload(); err != nil {
+				r.Log.Error("reload configuration, keeping the current one", logger.Err(err))
+			}
+		}
+	}
+}
+
+// Reload перечитывает конфигурацию. Если она не проходит проверку или
+// шаблоны не разбираются, не меняется ничего.
+func (r *Reloader) Reload() error {
+	cfg, err := config.Load(r.Path)
+	if err != nil {
+		return e.Wrap("load config failed with an error: ", err)
+	}
+
+	if err := r.Renderer.Reload(cfg.TemplatesDir); err != nil {
+		return e.Wrap("reload templates failed with an error: ", err)
+	}
+
+	level, err := logger.ParseLevel(cfg.LogLevel)
+	if err != nil {
+		return e.Wrap("parse log level failed with an error: ", err)
+	}
+
+	r.Level.Set(level)
+	r.Watcher.SetInterval(time.Duration(cfg.CheckInterval) * time.Minute)
+	r.Commands.SetSettings(commands.Settings{
+		AdminIDs:      cfg.AdminIDs,
+		BroadcastRate: cfg.BroadcastRate,
+	})
+
+	if fields := config.RestartRequired(r.started, cfg); len(fields) > 0 {
+		r.Log.Warn("configuration changes require restart", "fields", fields)
+	}
+
+	r.Log.Info("configuration reloaded", "log_level", level.String(), "check_interval", cfg.CheckInterval, "broadcast_rate", cfg.BroadcastRate, "admins", len(cfg.AdminIDs))
+
+	return nil
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/EfimoffN/authorBot/commands"
+	"github.com/EfimoffN/authorBot/config"
+	"github.com/EfimoffN/authorBot/render"
+	"github.com/EfimoffN/authorBot/watcher"
+)
+
+func Test_Reload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yml")
+
+	write := func(data string) {
+		if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("bot_token: token\nconnect_postgres: dsn\nlog_level: info\ncheck_interval: 30\n")
+
+	started, err := config.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	renderer, err := render.New("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	level := &slog.LevelVar{}
+	wtc := watcher.NewWatcher(nil, nil, nil, renderer, 30*time.Minute, log, ctx)
+	cmd := commands.NewBotCommands(nil, nil, wtc, commands.Settings{}, log, ctx)
+
+	r := NewReloader(path, started, level, cmd, wtc, renderer, log)
+
+	write("bot_token: new-token\nconnect_postgres: dsn\nlog_level: debug\ncheck_interval: 5\n")
+
+	if err := r.Reload(); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+
+	if got := level.Level(); got != slog.LevelDebug {
+		t.Errorf("level = %v, want DEBUG", got)
+	}
+
+	if got := wtc.Interval(); got != 5*time.Minute {
+		t.Errorf("interval = %v, want 5m", got)
+	}
+
+	write("bot_token: new-token\nconnect_postgres: dsn\nlog_level: error\ncheck_interval: 0\n")
+
+	if err := r.Reload(); err == nil {
+		t.Fatal("Reload() of an invalid config: want error")
+	}
+
+	if got := level.Level(); got != slog.LevelDebug {
+		t.Errorf("level after failed reload = %v, want DEBUG", got)
+	}
+
+	if got := wtc.Interval(); got != 5*time.Minute {
+		t.Errorf("interval after failed reload = %v, want 5m", got)
+	}
+}
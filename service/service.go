@@ -1,31 +1,169 @@
 package service
 
 import (
-	"log"
+	"context"
+	"log/slog"
+	"time"
 
 	"github.com/EfimoffN/authorBot/commands"
+	"github.com/EfimoffN/authorBot/events"
 	"github.com/EfimoffN/authorBot/lib/e"
+	"github.com/EfimoffN/authorBot/lib/logger"
+	"github.com/EfimoffN/authorBot/metrics"
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
 )
 
+// retryDelay — пауза перед новым запросом обновлений после ошибки.
+const retryDelay = 3 * time.Second
+
+// pruneEvery — через сколько отмеченных обновлений удалять старые отметки.
+const pruneEvery = 1000
+
+// Start получает обновления Telegram и передает их командам, пока не будет
+// отменен контекст команд.
 func Start(cmd *commands.Commands, bot *tgbotapi.BotAPI, timeout int) error {
-	u := tgbotapi.NewUpdate(0)
-	u.Timeout = timeout
+	p := &poller{
+		Fetch: func(offset int) ([]Update, error) {
+			return getUpdates(bot, offset, timeout)
+		},
+		Handle: func(log *slog.Logger, update Update) {
+			handle(cmd, log, update)
+		},
+		Event: cmd.Event,
+		Log:   cmd.Log,
+		CTX:   cmd.CTX,
+	}
+
+	return p.Run()
+}
 
-	updates, err := bot.GetUpdatesChan(u)
+// poller запрашивает обновления и передает в Handle те, что еще не
+// обрабатывались.
+type poller struct {
+	Fetch  func(offset int) ([]Update, error)
+	Handle func(log *slog.Logger, update Update)
+	Event  events.IEvent
+	Log    *slog.Logger
+	CTX    context.Context
+	// claimed — сколько обновлений отмечено с последней очистки отметок.
+	claimed int
+}
+
+// Run получает обновления, пока не будет отменен контекст. Работа
+// продолжается с обновления, следующего за последним обработанным.
+// Telegram начинает нумерацию заново только после недели без обновлений,
+// а отметки старше недели не учитываются, поэтому тогда обновления
+// запрашиваются с самого начала. Повторно присланные обновления
+// пропускаются по отметкам об обработке.
+func (p *poller) Run() error {
+	p.prune()
+
+	lastID, err := p.Event.LastUpdateID()
 	if err != nil {
-		return e.Wrap("Get updates chan", err)
+		return e.Wrap("Get last update ID", err)
+	}
+
+	offset := 0
+	if lastID > 0 {
+		offset = lastID + 1
 	}
 
-	for update := range updates {
-		if update.Message == nil { // ignore any non-Message Updates
+	p.Log.Info("receiving updates", "offset", offset)
+
+	for {
+		select {
+		case <-p.CTX.Done():
+			return nil
+		default:
+		}
+
+		updates, err := p.Fetch(offset)
+		if err != nil {
+			p.Log.Warn("get updates, retrying", "delay", retryDelay, logger.Err(err))
+
+			select {
+			case <-p.CTX.Done():
+				return nil
+			case <-time.After(retryDelay):
+			}
+
 			continue
 		}
 
-		if err := cmd.DoCommand(update.Message); err != nil {
-			log.Println("Processing commands: ", err.Error())
+		for i, update := range updates {
+			metrics.QueueDepth.WithLabelValues(metrics.QueueUpdates).Set(float64(len(updates) - i - 1))
+
+			p.process(update)
+			offset = update.UpdateID + 1
 		}
 	}
+}
+
+// process отмечает обновление обработанным и передает его в Handle, если
+// отметки не было. Отметка ставится до обработки, поэтому обновление
+// обрабатывается не больше одного раза: после сбоя посреди обработки оно
+// теряется, но команда не выполняется дважды. Если отметить обновление не
+// удалось, оно тоже пропускается.
+func (p *poller) process(update Update) {
+	log := p.Log.With(logger.KeyUpdateID, update.UpdateID)
+
+	claimed, err := p.Event.ClaimUpdate(update.UpdateID)
+	if err != nil {
+		log.Error("claim update, skipping", logger.Err(err))
+		return
+	}
+
+	if !claimed {
+		log.Info("update already processed, skipping")
+		return
+	}
+
+	p.Handle(log, update)
+
+	p.claimed++
+	if p.claimed >= pruneEvery {
+		p.prune()
+	}
+}
+
+// prune удаляет устаревшие отметки об обработанных обновлениях.
+func (p *poller) prune() {
+	p.claimed = 0
+
+	if err := p.Event.PruneUpdates(); err != nil {
+		p.Log.Warn("prune processed updates", logger.Err(err))
+	}
+}
+
+// handle передает обновление командам.
+func handle(cmd *commands.Commands, log *slog.Logger, update Update) {
+	switch {
+	case update.CallbackQuery != nil:
+		log = log.With(commands.CallbackFields(update.CallbackQuery)...)
+
+		if err := cmd.WithLogger(log).DoCallback(update.CallbackQuery); err != nil {
+			log.Error("processing callback", logger.Err(err))
+		}
+	case update.MyChatMember != nil:
+		log = log.With(commands.ChatMemberFields(update.MyChatMember)...)
+		log.Debug("processing chat member")
+
+		if err := cmd.WithLogger(log).DoChatMember(update.MyChatMember); err != nil {
+			log.Error("processing chat member", logger.Err(err))
+		}
+	case update.EditedMessage != nil:
+		log = log.With(commands.MessageFields(update.EditedMessage)...)
+		log.Debug("processing edited message")
+
+		if err := cmd.WithLogger(log).DoEditedMessage(update.EditedMessage); err != nil {
+			log.Error("processing edited message", logger.Err(err))
+		}
+	case update.Message != nil:
+		log = log.With(commands.MessageFields(update.Message)...)
+		log.Debug("processing message")
 
-	return nil
+		if err := cmd.WithLogger(log).DoCommand(update.Message); err != nil {
+			log.Error("processing commands", logger.Err(err))
+		}
+	}
 }
@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"reflect"
+	"testing"
+
+	"github.com/EfimoffN/authorBot/events"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+// fakeUpdates — отметки об обработанных обновлениях в памяти.
+type fakeUpdates struct {
+	events.IEvent
+	lastID    int
+	processed map[int]bool
+	failed    map[int]bool
+	prunes    int
+}
+
+func (f *fakeUpdates) LastUpdateID() (int, error) {
+	return f.lastID, nil
+}
+
+func (f *fakeUpdates) ClaimUpdate(updateID int) (bool, error) {
+	if f.failed[updateID] {
+		return false, errors.New("connection reset")
+	}
+
+	if f.processed[updateID] {
+		return false, nil
+	}
+
+	f.processed[updateID] = true
+
+	return true, nil
+}
+
+func (f *fakeUpdates) PruneUpdates() error {
+	f.prunes++
+	return nil
+}
+
+func batch(ids ...int) []Update {
+	updates := make([]Update, 0, len(ids))
+	for _, id := range ids {
+		updates = append(updates, Update{Update: tgbotapi.Update{UpdateID: id}})
+	}
+
+	return updates
+}
+
+// runPoller прогоняет poller по пачкам обновлений batches и возвращает
+// запрошенные смещения и обработанные обновления.
+func runPoller(t *testing.T, event *fakeUpdates, batches [][]Update) ([]int, []int) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	offsets := []int{}
+	handled := []int{}
+
+	p := &poller{
+		Fetch: func(offset int) ([]Update, error) {
+			offsets = append(offsets, offset)
+
+			if len(offsets) > len(batches) {
+				cancel()
+				return nil, nil
+			}
+
+			return batches[len(offsets)-1], nil
+		},
+		Handle: func(_ *slog.Logger, update Update) {
+			if !event.processed[update.UpdateID] {
+				t.Errorf("update %d is handled before it is claimed", update.UpdateID)
+			}
+
+			handled = append(handled, update.UpdateID)
+		},
+		Event: event,
+		Log:   slog.New(slog.NewTextHandler(io.Discard, nil)),
+		CTX:   ctx,
+	}
+
+	if err := p.Run(); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	return offsets, handled
+}
+
+func Test_pollerRun(t *testing.T) {
+	tests := []struct {
+		name        string
+		lastID      int
+		processed   []int
+		failed      []int
+		batches     [][]Update
+		wantOffsets []int
+		wantHandled []int
+	}{
+		{
+			name:        "first start",
+			batches:     [][]Update{batch(1, 2)},
+			wantOffsets: []int{0, 3},
+			wantHandled: []int{1, 2},
+		},
+		{
+			name:        "resume after the last processed update",
+			lastID:      10,
+			processed:   []int{10},
+			batches:     [][]Update{batch(11, 12)},
+			wantOffsets: []int{11, 13},
+			wantHandled: []int{11, 12},
+		},
+		{
+			name:        "skip updates claimed before a crash",
+			lastID:      10,
+			processed:   []int{10, 11},
+			batches:     [][]Update{batch(11, 12), batch(13)},
+			wantOffsets: []int{11, 13, 14},
+			wantHandled: []int{12, 13},
+		},
+		{
+			name:        "skip updates that could not be claimed",
+			batches:     [][]Update{batch(1, 2, 3)},
+			failed:      []int{2},
+			wantOffsets: []int{0, 4},
+			wantHandled: []int{1, 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := &fakeUpdates{lastID: tt.lastID, processed: map[int]bool{}, failed: map[int]bool{}}
+			for _, id := range tt.processed {
+				event.processed[id] = true
+			}
+
+			for _, id := range tt.failed {
+				event.failed[id] = true
+			}
+
+			offsets, handled := runPoller(t, event, tt.batches)
+
+			if !reflect.DeepEqual(offsets, tt.wantOffsets) {
+				t.Errorf("offsets = %v, want %v", offsets, tt.wantOffsets)
+			}
+
+			if !reflect.DeepEqual(handled, tt.wantHandled) {
+				t.Errorf("handled = %v, want %v", handled, tt.wantHandled)
+			}
+		})
+	}
+}
+
+func Test_pollerPrune(t *testing.T) {
+	ids := make([]int, 0, pruneEvery+1)
+	for id := 1; id <= pruneEvery+1; id++ {
+		ids = append(ids, id)
+	}
+
+	event := &fakeUpdates{processed: map[int]bool{}}
+	runPoller(t, event, [][]Update{batch(ids...)})
+
+	// Отметки чистятся при запуске и после каждых pruneEvery обновлений.
+	if event.prunes != 2 {
+		t.Errorf("prunes = %d, want 2", event.prunes)
+	}
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"encoding/json"
+	"net/url"
+	"strconv"
+
+	"github.com/EfimoffN/authorBot/commands"
+	"github.com/EfimoffN/authorBot/lib/e"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
+)
+
+// Update — обновление Telegram вместе с my_chat_member, которого нет в
+// tgbotapi.Update.
+type Update struct {
+	tgbotapi.Update
+	MyChatMember *commands.ChatMemberUpdated `json:"my_chat_member"`
+}
+
+// allowedUpdates — обновления, которые бот запрашивает у Telegram.
+var allowedUpdates = []string{"message", "edited_message", "callback_query", "my_chat_member"}
+
+// getUpdates запрашивает обновления начиная с offset, ожидая их не дольше
+// timeout секунд. tgbotapi.BotAPI.GetUpdates не подходит: он теряет
+// my_chat_member.
+func getUpdates(bot *tgbotapi.BotAPI, offset, timeout int) ([]Update, error) {
+	allowed, err := json.Marshal(allowedUpdates)
+	if err != nil {
+		return nil, e.Wrap("marshal allowed updates failed with an error: ", err)
+	}
+
+	v := url.Values{}
+	if offset != 0 {
+		v.Add("offset", strconv.Itoa(offset))
+	}
+	if timeout > 0 {
+		v.Add("timeout", strconv.Itoa(timeout))
+	}
+	v.Add("allowed_updates", string(allowed))
+
+	resp, err := bot.MakeRequest("getUpdates", v)
+	if err != nil {
+		return nil, e.Wrap("getUpdates failed with an error: ", err)
+	}
+
+	return decodeUpdates(resp.Result)
+}
+
+func decodeUpdates(data json.RawMessage) ([]Update, error) {
+	updates := []Update{}
+
+	if err := json.Unmarshal(data, &updates); err != nil {
+		return nil, e.Wrap("unmarshal updates failed with an error: ", err)
+	}
+
+	return updates, nil
+}
@@ -0,0 +1,47 @@
+package service
+
+import "testing"
+
+func Test_decodeUpdates(t *testing.T) {
+	data := []byte(`[
+		{"update_id": 1, "message": {"message_id": 10, "chat": {"id": 5, "type": "private"}, "text": "/start"}},
+		{"update_id": 2, "edited_message": {"message_id": 10, "chat": {"id": 5, "type": "private"}, "text": "https://author.today/work/1"}},
+		{"update_id": 3, "my_chat_member": {
+			"chat": {"id": -100, "type": "supergroup", "title": "Readers"},
+			"from": {"id": 5, "first_name": "Anna"},
+			"date": 1700000000,
+			"old_chat_member": {"user": {"id": 99, "first_name": "bot"}, "status": "member"},
+			"new_chat_member": {"user": {"id": 99, "first_name": "bot"}, "status": "left"}
+		}}
+	]`)
+
+	updates, err := decodeUpdates(data)
+	if err != nil {
+		t.Fatalf("decodeUpdates() error = %v", err)
+	}
+
+	if len(updates) != 3 {
+		t.Fatalf("decodeUpdates() = %d updates, want 3", len(updates))
+	}
+
+	if updates[0].UpdateID != 1 || updates[0].Message == nil || updates[0].Message.Text != "/start" {
+		t.Errorf("message update = %+v", updates[0])
+	}
+
+	if updates[1].EditedMessage == nil || updates[1].Message != nil {
+		t.Errorf("edited message update = %+v", updates[1])
+	}
+
+	member := updates[2].MyChatMember
+	if member == nil {
+		t.Fatal("my_chat_member is not decoded")
+	}
+
+	if member.Chat.ID != -100 || member.Chat.Title != "Readers" || member.From.ID != 5 || member.OldChatMember.Status != "member" || member.NewChatMember.Status != "left" {
+		t.Errorf("my_chat_member = %+v", member)
+	}
+
+	if _, err := decodeUpdates([]byte(`{"update_id": 1}`)); err == nil {
+		t.Error("decodeUpdates() of an object: want error")
+	}
+}
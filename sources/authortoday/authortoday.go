@@ -0,0 +1,416 @@
+package authortoday
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/EfimoffN/authorBot/atapi"
+	"github.com/EfimoffN/authorBot/lib/e"
+	"github.com/EfimoffN/authorBot/lib/logger"
+	"github.com/EfimoffN/authorBot/parser"
+	"github.com/EfimoffN/authorBot/sources"
+)
+
+const (
+	name    = "author.today"
+	baseURL = "https://author.today"
+)
+
+// errNoAPI — блог и комментарии доступны только через JSON API.
+var errNoAPI = errors.New("author.today API is disabled")
+
+// Source — площадка author.today. Для типов ссылок из apiKinds состояние
+// сначала запрашивается через JSON API, а при его ошибке разбирается HTML.
+type Source struct {
+	Parser   *parser.Parser
+	API      *atapi.Client
+	apiKinds map[string]bool
+}
+
+func NewSource(parser *parser.Parser, api *atapi.Client, apiKinds []string) *Source {
+	kinds := make(map[string]bool, len(apiKinds))
+	for _, k := range apiKinds {
+		kinds[k] = true
+	}
+
+	return &Source{
+		Parser:   parser,
+		API:      api,
+		apiKinds: kinds,
+	}
+}
+
+func (s *Source) Name() string {
+	return name
+}
+
+func (s *Source) Match(u *url.URL) bool {
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+
+	return host == name
+}
+
+func (s *Source) Canonicalize(u *url.URL) (string, string, error) {
+	link := baseURL + u.EscapedPath()
+
+	if id := parser.WorkID(link); id != "" {
+		return baseURL + "/work/" + id, sources.KindBook, nil
+	}
+
+	if login := parser.AuthorLogin(link); login != "" {
+		return baseURL + "/u/" + login, sources.KindAuthor, nil
+	}
+
+	if id := parser.SeriesID(link); id != "" {
+		return baseURL + "/work/series/" + id, sources.KindSeries, nil
+	}
+
+	return "", "", e.Wrap(u.String(), sources.ErrUnsupportedLink)
+}
+
+func (s *Source) Fetch(ctx context.Context, link, kind string) (*sources.State, error) {
+	switch kind {
+	case sources.KindAuthor:
+		return s.fetchAuthor(ctx, link)
+	case sources.KindSeries:
+		return s.fetchSeries(ctx, link)
+	default:
+		return s.fetchBook(ctx, link)
+	}
+}
+
+func (s *Source) Diff(old, new *sources.State) []sources.Change {
+	return sources.DiffStates(old, new)
+}
+
+// Comments загружает через API тексты последних count комментариев к
+// записи блога.
+func (s *Source) Comments(ctx context.Context, post sources.Item, count int) ([]string, error) {
+	if s.API == nil {
+		return nil, errNoAPI
+	}
+
+	comments, err := s.API.PostComments(ctx, post.ID)
+	if err != nil {
+		return nil, e.Wrap("api post comments failed with an error: ", err)
+	}
+
+	return lastComments(comments.Items, count), nil
+}
+
+func (s *Source) useAPI(kind string) bool {
+	return s.API != nil && s.apiKinds[kind]
+}
+
+func (s *Source) fetchBook(ctx context.Context, link string) (*sources.State, error) {
+	if s.useAPI(sources.KindBook) {
+		state, err := s.apiBook(ctx, link)
+		if err == nil {
+			return state, nil
+		}
+
+		slog.Warn("author.today API fallback to HTML", logger.KeyLink, link, logger.Err(err))
+	}
+
+	book, err := s.Parser.GetBook(ctx, link)
+	if parser.IsHidden(err) {
+		return nil, e.Wrap(link, sources.ErrHidden)
+	}
+
+	if err != nil {
+		return nil, e.Wrap("get book failed with an error: ", err)
+	}
+
+	return &sources.State{
+		Kind:       sources.KindBook,
+		Title:      book.Title,
+		Chapters:   book.Chapters,
+		Status:     bookStatus(book.Status),
+		Price:      book.Price,
+		Discount:   book.Discount,
+		Free:       book.Free,
+		HasPrice:   book.HasPrice,
+		Annotation: book.Annotation,
+	}, nil
+}
+
+func (s *Source) fetchAuthor(ctx context.Context, link string) (*sources.State, error) {
+	if s.useAPI(sources.KindAuthor) {
+		state, err := s.apiAuthor(ctx, link)
+		if err == nil {
+			return state, nil
+		}
+
+		slog.Warn("author.today API fallback to HTML", logger.KeyLink, link, logger.Err(err))
+	}
+
+	author, err := s.Parser.GetAuthor(ctx, link)
+	if err != nil {
+		return nil, e.Wrap("get author failed with an error: ", err)
+	}
+
+	state := &sources.State{
+		Kind:  sources.KindAuthor,
+		Title: author.Name,
+	}
+
+	for _, w := range author.Works {
+		addWork(state, w.ID, w.Title, w.SeriesID, w.SeriesTitle)
+	}
+
+	return state, nil
+}
+
+func (s *Source) fetchSeries(ctx context.Context, link string) (*sources.State, error) {
+	if s.useAPI(sources.KindSeries) {
+		state, err := s.apiSeries(ctx, link)
+		if err == nil {
+			return state, nil
+		}
+
+		slog.Warn("author.today API fallback to HTML", logger.KeyLink, link, logger.Err(err))
+	}
+
+	series, err := s.Parser.GetSeries(ctx, link)
+	if err != nil {
+		return nil, e.Wrap("get series failed with an error: ", err)
+	}
+
+	state := &sources.State{
+		Kind:  sources.KindSeries,
+		Title: series.Title,
+	}
+
+	for _, b := range series.Books {
+		state.Items = append(state.Items, sources.Item{
+			Kind:     sources.ItemVolume,
+			ID:       b.ID,
+			Title:    b.Title,
+			URL:      parser.WorkURL(b.ID),
+			Position: b.Position,
+			Status:   bookStatus(b.Status),
+		})
+	}
+
+	return state, nil
+}
+
+func (s *Source) apiBook(ctx context.Context, link string) (*sources.State, error) {
+	id := parser.WorkID(link)
+
+	work, err := s.API.Work(ctx, id)
+	if err != nil {
+		return nil, e.Wrap("api work failed with an error: ", err)
+	}
+
+	chapters, err := s.API.Chapters(ctx, id)
+	if err != nil {
+		return nil, e.Wrap("api chapters failed with an error: ", err)
+	}
+
+	state := &sources.State{
+		Kind:       sources.KindBook,
+		Title:      work.Title,
+		Status:     workStatus(work),
+		Annotation: strings.Join(strings.Fields(work.Annotation), " "),
+	}
+
+	setWorkPrice(state, work)
+
+	for _, c := range chapters {
+		if c.IsDraft {
+			continue
+		}
+
+		state.Chapters++
+
+		if c.PublishTime.After(state.Updated) {
+			state.Updated = c.PublishTime
+		}
+	}
+
+	if state.Free {
+		state.Price = 0
+		state.Discount = 0
+	}
+
+	return state, nil
+}
+
+func (s *Source) apiAuthor(ctx context.Context, link string) (*sources.State, error) {
+	login := parser.AuthorLogin(link)
+
+	profile, err := s.API.Author(ctx, login)
+	if err != nil {
+		return nil, e.Wrap("api author failed with an error: ", err)
+	}
+
+	works, err := s.API.AuthorWorks(ctx, login)
+	if err != nil {
+		return nil, e.Wrap("api author works failed with an error: ", err)
+	}
+
+	state := &sources.State{
+		Kind:  sources.KindAuthor,
+		Title: profile.FIO,
+	}
+
+	if state.Title == "" {
+		state.Title = profile.UserName
+	}
+
+	for _, w := range works.Items {
+		seriesID := ""
+		if w.SeriesID != 0 {
+			seriesID = strconv.Itoa(w.SeriesID)
+		}
+
+		addWork(state, strconv.Itoa(w.ID), w.Title, seriesID, w.SeriesTitle)
+	}
+
+	// Блог доступен только через API. Без него записи просто не
+	// попадают в состояние, а сохраненные ранее остаются в базе.
+	posts, err := s.API.AuthorPosts(ctx, login)
+	if err != nil {
+		slog.Warn("author.today API posts", logger.KeyLink, link, logger.Err(err))
+		return state, nil
+	}
+
+	for _, p := range posts.Items {
+		state.Items = append(state.Items, sources.Item{
+			Kind:     sources.ItemPost,
+			ID:       strconv.Itoa(p.ID),
+			Title:    p.Title,
+			URL:      baseURL + "/post/" + strconv.Itoa(p.ID),
+			Text:     p.Text,
+			Comments: p.CommentCount,
+		})
+	}
+
+	return state, nil
+}
+
+func (s *Source) apiSeries(ctx context.Context, link string) (*sources.State, error) {
+	series, err := s.API.Series(ctx, parser.SeriesID(link))
+	if err != nil {
+		return nil, e.Wrap("api series failed with an error: ", err)
+	}
+
+	works := series.Works
+	sort.SliceStable(works, func(i, j int) bool {
+		return works[i].SeriesOrder < works[j].SeriesOrder
+	})
+
+	state := &sources.State{
+		Kind:  sources.KindSeries,
+		Title: series.Title,
+	}
+
+	for i := range works {
+		state.Items = append(state.Items, sources.Item{
+			Kind:     sources.ItemVolume,
+			ID:       strconv.Itoa(works[i].ID),
+			Title:    works[i].Title,
+			URL:      parser.WorkURL(strconv.Itoa(works[i].ID)),
+			Position: i + 1,
+			Status:   workStatus(&works[i]),
+		})
+	}
+
+	return state, nil
+}
+
+// addWork добавляет в состояние произведение автора и, если его цикл
+// встречается впервые, сам цикл.
+func addWork(state *sources.State, id, title, seriesID, seriesTitle string) {
+	state.Items = append(state.Items, sources.Item{
+		Kind:        sources.ItemWork,
+		ID:          id,
+		Title:       title,
+		URL:         parser.WorkURL(id),
+		SeriesID:    seriesID,
+		SeriesTitle: seriesTitle,
+	})
+
+	if seriesID == "" {
+		return
+	}
+
+	for _, i := range state.Items {
+		if i.Kind == sources.ItemSeries && i.ID == seriesID {
+			return
+		}
+	}
+
+	state.Items = append(state.Items, sources.Item{
+		Kind:  sources.ItemSeries,
+		ID:    seriesID,
+		Title: seriesTitle,
+		URL:   baseURL + "/work/series/" + seriesID,
+	})
+}
+
+func bookStatus(status string) string {
+	switch status {
+	case parser.StatusCompleted:
+		return sources.StatusCompleted
+	case parser.StatusFrozen:
+		return sources.StatusFrozen
+	case parser.StatusHidden:
+		return sources.StatusHidden
+	default:
+		return sources.StatusInProgress
+	}
+}
+
+// setWorkPrice заполняет цену книги из ответа API так же, как parser.ParseBook
+// со страницы: книга бесплатна, только если API отметил ее бесплатной, а
+// нулевая цена значит, что цены нет — книга не продается.
+func setWorkPrice(state *sources.State, work *atapi.Work) {
+	state.Free = work.IsFree
+	state.HasPrice = work.IsFree || work.Price > 0
+
+	if !state.HasPrice || state.Free {
+		return
+	}
+
+	state.Price = int(work.Price)
+	state.Discount = int(work.Discount)
+}
+
+// lastComments возвращает тексты count самых новых комментариев.
+func lastComments(comments []atapi.Comment, count int) []string {
+	sorted := append([]atapi.Comment(nil), comments...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].CreationTime.After(sorted[j].CreationTime)
+	})
+
+	if count < len(sorted) {
+		sorted = sorted[:count]
+	}
+
+	texts := make([]string, 0, len(sorted))
+	for _, c := range sorted {
+		texts = append(texts, c.Text)
+	}
+
+	return texts
+}
+
+func workStatus(work *atapi.Work) string {
+	switch {
+	case work.IsDraft:
+		return sources.StatusHidden
+	case work.Status == atapi.WorkCompleted:
+		return sources.StatusCompleted
+	case work.Status == atapi.WorkFrozen:
+		return sources.StatusFrozen
+	default:
+		return sources.StatusInProgress
+	}
+}
@@ -0,0 +1,128 @@
+package authortoday
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/EfimoffN/authorBot/atapi"
+	"github.com/EfimoffN/authorBot/sources"
+)
+
+func Test_Canonicalize(t *testing.T) {
+	registry := sources.NewRegistry(NewSource(nil, nil, nil))
+
+	tests := []struct {
+		link     string
+		wantLink string
+		wantKind string
+		wantErr  error
+	}{
+		{link: "https://author.today/work/123", wantLink: "https://author.today/work/123", wantKind: sources.KindBook},
+		{link: "http://www.author.today/work/123/?utm=1#top", wantLink: "https://author.today/work/123", wantKind: sources.KindBook},
+		{link: "https://author.today/u/login/works", wantLink: "https://author.today/u/login", wantKind: sources.KindAuthor},
+		{link: "https://author.today/work/series/7", wantLink: "https://author.today/work/series/7", wantKind: sources.KindSeries},
+		{link: "https://author.today/post/1", wantErr: sources.ErrUnsupportedLink},
+		{link: "https://example.com/work/123", wantErr: sources.ErrUnsupported},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.link, func(t *testing.T) {
+			link, kind, err := registry.Canonicalize(tt.link)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("Canonicalize() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Canonicalize() error = %v", err)
+			}
+
+			if link != tt.wantLink || kind != tt.wantKind {
+				t.Errorf("Canonicalize() = %v, %v, want %v, %v", link, kind, tt.wantLink, tt.wantKind)
+			}
+		})
+	}
+}
+
+func Test_UnsupportedError(t *testing.T) {
+	registry := sources.NewRegistry(NewSource(nil, nil, nil))
+
+	_, err := registry.Lookup("https://example.com/book/1")
+
+	var unsupported *sources.UnsupportedError
+	if !errors.As(err, &unsupported) {
+		t.Fatalf("Lookup() error = %v, want UnsupportedError", err)
+	}
+
+	if len(unsupported.Supported) != 1 || unsupported.Supported[0] != "author.today" {
+		t.Errorf("Lookup() supported = %v", unsupported.Supported)
+	}
+}
+
+func Test_setWorkPrice(t *testing.T) {
+	tests := []struct {
+		name string
+		work atapi.Work
+		want sources.State
+	}{
+		{
+			name: "for sale",
+			work: atapi.Work{Price: 150, Discount: 25},
+			want: sources.State{Price: 150, Discount: 25, HasPrice: true},
+		},
+		{
+			name: "free",
+			work: atapi.Work{IsFree: true},
+			want: sources.State{Free: true, HasPrice: true},
+		},
+		{
+			name: "not for sale",
+			work: atapi.Work{},
+			want: sources.State{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := sources.State{}
+			setWorkPrice(&got, &tt.work)
+
+			if got.Price != tt.want.Price || got.Discount != tt.want.Discount || got.Free != tt.want.Free || got.HasPrice != tt.want.HasPrice {
+				t.Errorf("setWorkPrice() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_lastComments(t *testing.T) {
+	day := func(d int) time.Time {
+		return time.Date(2022, 8, d, 12, 0, 0, 0, time.UTC)
+	}
+
+	comments := []atapi.Comment{
+		{Text: "Жду третий том.", CreationTime: day(2)},
+		{Text: "Когда продолжение?", CreationTime: day(4)},
+		{Text: "Будет розыгрыш?", CreationTime: day(3)},
+	}
+
+	tests := []struct {
+		name  string
+		count int
+		want  []string
+	}{
+		{name: "newest", count: 2, want: []string{"Когда продолжение?", "Будет розыгрыш?"}},
+		{name: "more than loaded", count: 5, want: []string{"Когда продолжение?", "Будет розыгрыш?", "Жду третий том."}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := lastComments(comments, tt.count); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("lastComments() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
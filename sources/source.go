@@ -0,0 +1,134 @@
+package sources
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+)
+
+// Типы отслеживаемых ссылок.
+const (
+	KindBook   = "book"
+	KindAuthor = "author"
+	KindSeries = "series"
+)
+
+// Статусы книги.
+const (
+	StatusInProgress = "in_progress"
+	StatusCompleted  = "completed"
+	StatusFrozen     = "frozen"
+	StatusHidden     = "hidden"
+)
+
+// Типы элементов, из которых состоят страницы автора и цикла.
+const (
+	ItemWork   = "work"
+	ItemSeries = "series"
+	ItemVolume = "volume"
+	ItemPost   = "post"
+)
+
+var (
+	ErrUnsupported     = errors.New("unsupported platform")
+	ErrUnsupportedLink = errors.New("unsupported link on a supported platform")
+	// ErrHidden возвращается из Fetch, когда страница удалена, скрыта
+	// или перенесена в черновики.
+	ErrHidden = errors.New("page is hidden")
+)
+
+// UnsupportedError сообщает, что для ссылки нет подходящей площадки,
+// и перечисляет поддерживаемые.
+type UnsupportedError struct {
+	Host      string
+	Supported []string
+}
+
+func (err *UnsupportedError) Error() string {
+	return "unsupported host '" + err.Host + "', supported: " + strings.Join(err.Supported, ", ")
+}
+
+func (err *UnsupportedError) Is(target error) bool {
+	return target == ErrUnsupported
+}
+
+// Source описывает площадку с книгами. Чтобы добавить новую площадку,
+// достаточно реализовать этот интерфейс и зарегистрировать его в Registry.
+type Source interface {
+	// Name возвращает имя площадки для показа пользователю.
+	Name() string
+	// Match сообщает, относится ли ссылка к этой площадке.
+	Match(u *url.URL) bool
+	// Canonicalize приводит ссылку к единому виду и определяет ее тип.
+	Canonicalize(u *url.URL) (link string, kind string, err error)
+	// Fetch загружает страницу и разбирает ее в нормализованное состояние.
+	Fetch(ctx context.Context, link, kind string) (*State, error)
+	// Diff сравнивает прошлое и новое состояние. Для первой проверки
+	// old равен nil и изменений нет.
+	Diff(old, new *State) []Change
+}
+
+// CommentSource — необязательное расширение Source для площадок, которые
+// отдают текст комментариев к записям блога. По нему комментарии отбираются
+// по ключевым словам подписки.
+type CommentSource interface {
+	// Comments возвращает тексты последних count комментариев к записи.
+	Comments(ctx context.Context, post Item, count int) ([]string, error)
+}
+
+// Registry хранит зарегистрированные площадки.
+type Registry struct {
+	sources []Source
+}
+
+func NewRegistry(sources ...Source) *Registry {
+	return &Registry{
+		sources: sources,
+	}
+}
+
+func (r *Registry) Register(source Source) {
+	r.sources = append(r.sources, source)
+}
+
+// Supported возвращает имена поддерживаемых площадок.
+func (r *Registry) Supported() []string {
+	names := make([]string, 0, len(r.sources))
+	for _, s := range r.sources {
+		names = append(names, s.Name())
+	}
+
+	return names
+}
+
+// Lookup находит площадку для ссылки.
+func (r *Registry) Lookup(link string) (Source, error) {
+	u, err := url.Parse(strings.TrimSpace(link))
+	if err != nil || u.Host == "" {
+		return nil, &UnsupportedError{Host: link, Supported: r.Supported()}
+	}
+
+	for _, s := range r.sources {
+		if s.Match(u) {
+			return s, nil
+		}
+	}
+
+	return nil, &UnsupportedError{Host: u.Host, Supported: r.Supported()}
+}
+
+// Canonicalize находит площадку для ссылки и приводит ссылку к единому виду.
+func (r *Registry) Canonicalize(link string) (string, string, error) {
+	source, err := r.Lookup(link)
+	if err != nil {
+		return "", "", err
+	}
+
+	u, err := url.Parse(strings.TrimSpace(link))
+	if err != nil {
+		return "", "", err
+	}
+
+	return source.Canonicalize(u)
+}
@@ -0,0 +1,154 @@
+package sources
+
+import "time"
+
+// State — нормализованное состояние отслеживаемой страницы, одинаковое
+// для всех площадок.
+type State struct {
+	Kind     string
+	Title    string
+	Chapters int
+	Status   string
+	Price    int
+	Discount int
+	Free     bool
+	// HasPrice равен false, если цена еще ни разу не была получена.
+	HasPrice bool
+	// Updated — время публикации последней главы, если площадка его сообщает.
+	Updated    time.Time
+	Annotation string
+	Items      []Item
+}
+
+// Item — произведение автора, цикл, том цикла или запись в блоге.
+type Item struct {
+	Kind        string
+	ID          string
+	Title       string
+	URL         string
+	Position    int
+	Status      string
+	SeriesID    string
+	SeriesTitle string
+	// Text и Comments заполняются только для записей в блоге.
+	Text     string
+	Comments int
+}
+
+// Типы изменений.
+const (
+	ChangeChapters     = "chapters"
+	ChangeStatus       = "status"
+	ChangePrice        = "price"
+	ChangeNewWork      = "new_work"
+	ChangeNewSeries    = "new_series"
+	ChangeSeriesWork   = "series_work"
+	ChangeNewVolume    = "new_volume"
+	ChangeVolumeStatus = "volume_status"
+	ChangeNewPost      = "new_post"
+	ChangeComments     = "comments"
+	ChangeAnnotation   = "annotation"
+)
+
+// Change — одно обнаруженное изменение.
+type Change struct {
+	Type      string
+	Title     string
+	Count     int
+	OldStatus string
+	NewStatus string
+	OldPrice  int
+	NewPrice  int
+	OldFree   bool
+	NewFree   bool
+	Discount  int
+	Time      time.Time
+	Item      Item
+	// Comments — тексты новых комментариев для ChangeComments, если
+	// площадка их отдает.
+	Comments []string
+}
+
+// DiffStates — общее сравнение состояний, которым могут пользоваться
+// площадки в своей реализации Diff.
+func DiffStates(old, new *State) []Change {
+	if old == nil || new == nil {
+		return nil
+	}
+
+	changes := []Change{}
+
+	if new.Chapters > old.Chapters && old.Status != StatusHidden {
+		changes = append(changes, Change{Type: ChangeChapters, Title: new.Title, Count: new.Chapters - old.Chapters, Time: new.Updated})
+	}
+
+	if old.Status != "" && new.Status != "" && old.Status != new.Status {
+		changes = append(changes, Change{Type: ChangeStatus, Title: new.Title, OldStatus: old.Status, NewStatus: new.Status})
+	}
+
+	if old.HasPrice && new.HasPrice && (old.Price != new.Price || old.Free != new.Free) {
+		changes = append(changes, Change{
+			Type:     ChangePrice,
+			Title:    new.Title,
+			OldPrice: old.Price,
+			NewPrice: new.Price,
+			OldFree:  old.Free,
+			NewFree:  new.Free,
+			Discount: new.Discount,
+		})
+	}
+
+	if old.Annotation != "" && new.Annotation != "" && old.Annotation != new.Annotation {
+		changes = append(changes, Change{Type: ChangeAnnotation, Title: new.Title})
+	}
+
+	return append(changes, diffItems(old, new)...)
+}
+
+func diffItems(old, new *State) []Change {
+	known := make(map[string]Item, len(old.Items))
+	// Записи блога, опубликованные до первой загрузки блога, не считаются
+	// новыми.
+	hasPosts := false
+
+	for _, i := range old.Items {
+		known[i.Kind+i.ID] = i
+		hasPosts = hasPosts || i.Kind == ItemPost
+	}
+
+	changes := []Change{}
+
+	for _, i := range new.Items {
+		prev, ok := known[i.Kind+i.ID]
+
+		switch {
+		case i.Kind == ItemWork && !ok:
+			changes = append(changes, workChange(new.Title, i, known))
+		case i.Kind == ItemVolume && !ok:
+			changes = append(changes, Change{Type: ChangeNewVolume, Title: new.Title, Item: i})
+		case i.Kind == ItemVolume && prev.Status != "" && prev.Status != i.Status:
+			changes = append(changes, Change{Type: ChangeVolumeStatus, Title: new.Title, OldStatus: prev.Status, NewStatus: i.Status, Item: i})
+		case i.Kind == ItemPost && !ok && hasPosts:
+			changes = append(changes, Change{Type: ChangeNewPost, Title: new.Title, Item: i})
+		case i.Kind == ItemPost && ok && i.Comments > prev.Comments:
+			changes = append(changes, Change{Type: ChangeComments, Title: new.Title, Count: i.Comments - prev.Comments, Item: i})
+		}
+	}
+
+	return changes
+}
+
+// workChange определяет, начал ли автор новым произведением цикл.
+// Новый цикл запоминается в known, чтобы следующие книги того же цикла
+// считались добавленными в него.
+func workChange(title string, work Item, known map[string]Item) Change {
+	switch {
+	case work.SeriesID == "":
+		return Change{Type: ChangeNewWork, Title: title, Item: work}
+	case known[ItemSeries+work.SeriesID].ID == "":
+		known[ItemSeries+work.SeriesID] = Item{Kind: ItemSeries, ID: work.SeriesID, Title: work.SeriesTitle}
+		return Change{Type: ChangeNewSeries, Title: title, Item: work}
+	default:
+		return Change{Type: ChangeSeriesWork, Title: title, Item: work}
+	}
+}
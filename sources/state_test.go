@@ -0,0 +1,112 @@
+package sources
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_DiffStates(t *testing.T) {
+	tests := []struct {
+		name string
+		old  *State
+		new  *State
+		want []string
+	}{
+		{
+			name: "first check",
+			old:  nil,
+			new:  &State{Title: "book", Chapters: 3, Status: StatusInProgress},
+			want: nil,
+		},
+		{
+			name: "new chapters and completed",
+			old:  &State{Title: "book", Chapters: 3, Status: StatusInProgress},
+			new:  &State{Title: "book", Chapters: 5, Status: StatusCompleted},
+			want: []string{ChangeChapters, ChangeStatus},
+		},
+		{
+			name: "price changed",
+			old:  &State{Title: "book", Price: 200, HasPrice: true},
+			new:  &State{Title: "book", Price: 150, Discount: 25, HasPrice: true},
+			want: []string{ChangePrice},
+		},
+		{
+			name: "first price",
+			old:  &State{Title: "book"},
+			new:  &State{Title: "book", Price: 150, HasPrice: true},
+			want: []string{},
+		},
+		{
+			name: "author works",
+			old: &State{Title: "author", Items: []Item{
+				{Kind: ItemWork, ID: "1", SeriesID: "7"},
+				{Kind: ItemSeries, ID: "7"},
+			}},
+			new: &State{Title: "author", Items: []Item{
+				{Kind: ItemWork, ID: "1", SeriesID: "7"},
+				{Kind: ItemWork, ID: "2", SeriesID: "7"},
+				{Kind: ItemWork, ID: "3", SeriesID: "8"},
+				{Kind: ItemWork, ID: "4", SeriesID: "8"},
+				{Kind: ItemWork, ID: "5"},
+				{Kind: ItemSeries, ID: "7"},
+				{Kind: ItemSeries, ID: "8"},
+			}},
+			want: []string{ChangeSeriesWork, ChangeNewSeries, ChangeSeriesWork, ChangeNewWork},
+		},
+		{
+			name: "series volumes",
+			old: &State{Title: "series", Items: []Item{
+				{Kind: ItemVolume, ID: "1", Status: StatusInProgress},
+			}},
+			new: &State{Title: "series", Items: []Item{
+				{Kind: ItemVolume, ID: "1", Status: StatusCompleted},
+				{Kind: ItemVolume, ID: "2", Status: StatusInProgress},
+			}},
+			want: []string{ChangeVolumeStatus, ChangeNewVolume},
+		},
+		{
+			name: "annotation edited",
+			old:  &State{Title: "book", Annotation: "old"},
+			new:  &State{Title: "book", Annotation: "new"},
+			want: []string{ChangeAnnotation},
+		},
+		{
+			name: "first blog load",
+			old:  &State{Title: "author"},
+			new: &State{Title: "author", Items: []Item{
+				{Kind: ItemPost, ID: "1", Comments: 3},
+			}},
+			want: []string{},
+		},
+		{
+			name: "blog posts and comments",
+			old: &State{Title: "author", Items: []Item{
+				{Kind: ItemPost, ID: "1", Comments: 3},
+			}},
+			new: &State{Title: "author", Items: []Item{
+				{Kind: ItemPost, ID: "1", Comments: 5},
+				{Kind: ItemPost, ID: "2"},
+			}},
+			want: []string{ChangeComments, ChangeNewPost},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes := DiffStates(tt.old, tt.new)
+
+			var got []string
+			if changes != nil {
+				got = []string{}
+			}
+
+			for _, c := range changes {
+				got = append(got, c.Type)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DiffStates() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
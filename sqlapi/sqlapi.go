@@ -2,8 +2,10 @@ package sqlapi
 
 import (
 	"context"
+	"time"
 
 	"github.com/EfimoffN/authorBot/lib/e"
+	"github.com/EfimoffN/authorBot/metrics"
 	"github.com/jmoiron/sqlx"
 )
 
@@ -16,7 +18,45 @@ type ISQLAPI interface {
 	AddUser(ctx context.Context, userN string, userID int, chatID int64) error
 	RemoveRefByUserIDLinkID(userID int, linkID string) error
 	GetLinkByLink(lnk string) (*LinkRow, error)
-	AddLink(ctx context.Context, link, linkID string) error
+	AddLink(ctx context.Context, link, linkID, kind string) error
+	GetAllLinks() ([]*LinkRow, error)
+	UpdateLink(ctx context.Context, link LinkRow) error
+	GetSubscribers(linkID string) ([]*SubscriberRow, error)
+	SetPriceAlert(ctx context.Context, userID int, linkID string, enabled bool, below int) error
+	SetChapterAlert(ctx context.Context, userID int, linkID string, enabled bool) error
+	SetStatusAlert(ctx context.Context, userID int, linkID string, enabled bool) error
+	GetLastPrice(linkID string) (*PriceRow, error)
+	AddPrice(ctx context.Context, price PriceRow) error
+	GetLinkItems(linkID string) ([]*ItemRow, error)
+	AddLinkItem(ctx context.Context, item ItemRow) error
+	UpdateLinkItem(ctx context.Context, item ItemRow) error
+	SetDelivery(ctx context.Context, userID int, delivery string, hour int) error
+	SetLastDigest(ctx context.Context, userID int, sent time.Time) error
+	AddNotice(ctx context.Context, notice NoticeRow) error
+	GetPendingUsers() ([]*UserRow, error)
+	GetNotices(userID int) ([]*NoticeRow, error)
+	RemoveNotices(ctx context.Context, userID int, before time.Time) error
+	SetTimezone(ctx context.Context, userID int, timezone string) error
+	SetQuietHours(ctx context.Context, userID int, start, end int) error
+	GetRefByID(refID string) (*RefRow, error)
+	GetUserRefs(userID int) ([]*RefRow, error)
+	SetChatID(ctx context.Context, userID int, chatID int64) error
+	MigrateUser(ctx context.Context, fromID, toID int64) error
+	SetLang(ctx context.Context, userID int, lang string) error
+	UpdateRefFilters(ctx context.Context, ref RefRow) error
+	SetPaused(ctx context.Context, userID int, linkID string, paused bool) error
+	SetSnooze(ctx context.Context, userID int, linkID string, until time.Time) error
+	SetUserSnooze(ctx context.Context, userID int, until time.Time) error
+	RemoveUser(userID int) error
+	RemoveOrphanLinks(ctx context.Context) (int64, error)
+	SetBanned(ctx context.Context, userID int, banned bool) error
+	SetBlocked(ctx context.Context, userID int, blocked bool) error
+	ResetChatID(ctx context.Context, chatID int64) (int64, error)
+	GetActiveUsers() ([]*UserRow, error)
+	GetStats() (*StatsRow, error)
+	GetLastUpdateID(ctx context.Context, after time.Time) (int, error)
+	ClaimUpdate(ctx context.Context, updateID int) (bool, error)
+	PruneUpdates(ctx context.Context, before time.Time) error
 }
 
 type SQLAPI struct {
@@ -43,6 +83,8 @@ func ConnectDB(databaseURL string) (*sqlx.DB, error) {
 }
 
 func (api *SQLAPI) GetUserByID(userID int) (*UserRow, error) {
+	defer metrics.ObserveQuery("GetUserByID", time.Now())
+
 	userRow := []UserRow{}
 
 	err := api.db.Select(&userRow, "SELECT * FROM prj_user WHERE userid = $1;", userID)
@@ -58,6 +100,8 @@ func (api *SQLAPI) GetUserByID(userID int) (*UserRow, error) {
 }
 
 func (api *SQLAPI) GetLinkByLink(lnk string) (*LinkRow, error) {
+	defer metrics.ObserveQuery("GetLinkByLink", time.Now())
+
 	linkRow := []LinkRow{}
 
 	err := api.db.Select(&linkRow, "SELECT * FROM prj_link WHERE link = $1;", lnk)
@@ -73,6 +117,8 @@ func (api *SQLAPI) GetLinkByLink(lnk string) (*LinkRow, error) {
 }
 
 func (api *SQLAPI) GetRefLinksUser(userID int) ([]*RefRow, error) {
+	defer metrics.ObserveQuery("GetRefLinksUser", time.Now())
+
 	refRow := []*RefRow{}
 
 	err := api.db.Select(&refRow, "SELECT * FROM ref_link_user WHERE userid = $1;", userID)
@@ -84,9 +130,11 @@ func (api *SQLAPI) GetRefLinksUser(userID int) ([]*RefRow, error) {
 }
 
 func (api *SQLAPI) GetLinksUser(userID int) ([]*LinkRow, error) {
+	defer metrics.ObserveQuery("GetLinksUser", time.Now())
+
 	linkRow := []*LinkRow{}
 
-	err := api.db.Select(&linkRow, "SELECT prj_link.linkid, prj_link.link FROM ref_link_user JOIN prj_link ON prj_link.linkid = ref_link_user.linkid WHERE ref_link_user.userid = $1;", userID)
+	err := api.db.Select(&linkRow, "SELECT prj_link.linkid, prj_link.link, prj_link.title, prj_link.kind, prj_link.volumes, prj_link.checked, prj_link.lastcheck, ref_link_user.paused, ref_link_user.snoozeuntil FROM ref_link_user JOIN prj_link ON prj_link.linkid = ref_link_user.linkid WHERE ref_link_user.userid = $1;", userID)
 	if err != nil {
 		return nil, e.Wrap("GetLinksUser api.db.Select failed with an error: ", err)
 	}
@@ -95,6 +143,8 @@ func (api *SQLAPI) GetLinksUser(userID int) ([]*LinkRow, error) {
 }
 
 func (api *SQLAPI) GetRefByIDLinkUser(userID int, linkID string) (*RefRow, error) {
+	defer metrics.ObserveQuery("GetRefByIDLinkUser", time.Now())
+
 	refRow := []RefRow{}
 
 	err := api.db.Select(&refRow, "SELECT * FROM ref_link_user WHERE userid = $1 AND linkid = $2;", userID, linkID)
@@ -110,6 +160,8 @@ func (api *SQLAPI) GetRefByIDLinkUser(userID int, linkID string) (*RefRow, error
 }
 
 func (api *SQLAPI) AddUser(ctx context.Context, userN string, userID int, chatID int64) error {
+	defer metrics.ObserveQuery("AddUser", time.Now())
+
 	const query = `INSERT INTO prj_user(userid, nameuser, chatid) VALUES (:userid, :nameuser, :chatid) ON CONFLICT DO NOTHING;`
 
 	user := UserRow{
@@ -125,12 +177,15 @@ func (api *SQLAPI) AddUser(ctx context.Context, userN string, userID int, chatID
 	return nil
 }
 
-func (api *SQLAPI) AddLink(ctx context.Context, link, linkID string) error {
-	const query = `INSERT INTO prj_link(linkid, link) VALUES (:linkid, :link) ON CONFLICT DO NOTHING;`
+func (api *SQLAPI) AddLink(ctx context.Context, link, linkID, kind string) error {
+	defer metrics.ObserveQuery("AddLink", time.Now())
+
+	const query = `INSERT INTO prj_link(linkid, link, kind) VALUES (:linkid, :link, :kind) ON CONFLICT DO NOTHING;`
 
 	linkR := LinkRow{
 		LinkID: linkID,
 		Link:   link,
+		Kind:   kind,
 	}
 
 	if _, err := api.db.NamedExecContext(ctx, query, linkR); err != nil {
@@ -141,6 +196,8 @@ func (api *SQLAPI) AddLink(ctx context.Context, link, linkID string) error {
 }
 
 func (api *SQLAPI) AddRefLinkUser(ctx context.Context, refID string, linkID string, userID int) error {
+	defer metrics.ObserveQuery("AddRefLinkUser", time.Now())
+
 	const query = `INSERT INTO ref_link_user(refid, linkid, userid) VALUES (:refid, :linkid, :userid) ON CONFLICT DO NOTHING;`
 
 	refR := RefRow{
@@ -157,6 +214,8 @@ func (api *SQLAPI) AddRefLinkUser(ctx context.Context, refID string, linkID stri
 }
 
 func (api *SQLAPI) RemoveRefByUserIDLinkID(userID int, linkID string) error {
+	defer metrics.ObserveQuery("RemoveRefByUserIDLinkID", time.Now())
+
 	_, err := api.db.Exec("DELETE FROM ref_link_user WHERE userID = $1 AND linkid = $2;", userID, linkID)
 	if err != nil {
 		return e.Wrap("DELETE row ref link by userid adn linkid failed with an error: ", err)
@@ -166,6 +225,8 @@ func (api *SQLAPI) RemoveRefByUserIDLinkID(userID int, linkID string) error {
 }
 
 func (api *SQLAPI) RemoveUser(userID int) error {
+	defer metrics.ObserveQuery("RemoveUser", time.Now())
+
 	_, err := api.db.Exec("DELETE FROM prj_user WHERE userid = $1;", userID)
 	if err != nil {
 		return e.Wrap("DELETE row user failed with an error: ", err)
@@ -173,3 +234,498 @@ func (api *SQLAPI) RemoveUser(userID int) error {
 
 	return nil
 }
+
+// GetAllLinks возвращает ссылки, у которых есть хотя бы один подписчик,
+// не поставивший их на паузу.
+func (api *SQLAPI) GetAllLinks() ([]*LinkRow, error) {
+	defer metrics.ObserveQuery("GetAllLinks", time.Now())
+
+	linkRow := []*LinkRow{}
+
+	err := api.db.Select(&linkRow, "SELECT DISTINCT prj_link.* FROM prj_link JOIN ref_link_user ON ref_link_user.linkid = prj_link.linkid JOIN prj_user ON prj_user.userid = ref_link_user.userid WHERE ref_link_user.paused = false AND ref_link_user.snoozeuntil < now() AND prj_user.snoozeuntil < now();")
+	if err != nil {
+		return nil, e.Wrap("GetAllLinks api.db.Select failed with an error: ", err)
+	}
+
+	return linkRow, err
+}
+
+func (api *SQLAPI) UpdateLink(ctx context.Context, link LinkRow) error {
+	defer metrics.ObserveQuery("UpdateLink", time.Now())
+
+	const query = `UPDATE prj_link SET title = :title, chapters = :chapters, checked = :checked, status = :status, volumes = :volumes, annotation = :annotation, lastcheck = now() WHERE linkid = :linkid;`
+
+	if _, err := api.db.NamedExecContext(ctx, query, link); err != nil {
+		return e.Wrap("UPDATE link failed with an error: ", err)
+	}
+
+	return nil
+}
+
+func (api *SQLAPI) GetSubscribers(linkID string) ([]*SubscriberRow, error) {
+	defer metrics.ObserveQuery("GetSubscribers", time.Now())
+
+	subRow := []*SubscriberRow{}
+
+	err := api.db.Select(&subRow, "SELECT prj_user.userid, prj_user.chatid, ref_link_user.pricealert, ref_link_user.pricebelow, ref_link_user.chapteralert, ref_link_user.statusalert, ref_link_user.blogalert, ref_link_user.commentalert, ref_link_user.annotationalert, ref_link_user.include, ref_link_user.exclude, prj_user.delivery, prj_user.timezone, prj_user.quietstart, prj_user.quietend, ref_link_user.paused, ref_link_user.snoozeuntil, prj_user.snoozeuntil AS usersnoozeuntil, prj_user.lang FROM ref_link_user JOIN prj_user ON prj_user.userid = ref_link_user.userid WHERE ref_link_user.linkid = $1 AND NOT prj_user.banned AND NOT prj_user.blocked;", linkID)
+	if err != nil {
+		return nil, e.Wrap("GetSubscribers api.db.Select failed with an error: ", err)
+	}
+
+	return subRow, err
+}
+
+func (api *SQLAPI) SetPriceAlert(ctx context.Context, userID int, linkID string, enabled bool, below int) error {
+	defer metrics.ObserveQuery("SetPriceAlert", time.Now())
+
+	_, err := api.db.ExecContext(ctx, "UPDATE ref_link_user SET pricealert = $1, pricebelow = $2 WHERE userid = $3 AND linkid = $4;", enabled, below, userID, linkID)
+	if err != nil {
+		return e.Wrap("UPDATE ref_link_user price alert failed with an error: ", err)
+	}
+
+	return nil
+}
+
+func (api *SQLAPI) SetChapterAlert(ctx context.Context, userID int, linkID string, enabled bool) error {
+	defer metrics.ObserveQuery("SetChapterAlert", time.Now())
+
+	_, err := api.db.ExecContext(ctx, "UPDATE ref_link_user SET chapteralert = $1 WHERE userid = $2 AND linkid = $3;", enabled, userID, linkID)
+	if err != nil {
+		return e.Wrap("UPDATE ref_link_user chapter alert failed with an error: ", err)
+	}
+
+	return nil
+}
+
+func (api *SQLAPI) SetStatusAlert(ctx context.Context, userID int, linkID string, enabled bool) error {
+	defer metrics.ObserveQuery("SetStatusAlert", time.Now())
+
+	_, err := api.db.ExecContext(ctx, "UPDATE ref_link_user SET statusalert = $1 WHERE userid = $2 AND linkid = $3;", enabled, userID, linkID)
+	if err != nil {
+		return e.Wrap("UPDATE ref_link_user status alert failed with an error: ", err)
+	}
+
+	return nil
+}
+
+func (api *SQLAPI) GetLastPrice(linkID string) (*PriceRow, error) {
+	defer metrics.ObserveQuery("GetLastPrice", time.Now())
+
+	priceRow := []PriceRow{}
+
+	err := api.db.Select(&priceRow, "SELECT * FROM link_price WHERE linkid = $1 ORDER BY created DESC LIMIT 1;", linkID)
+	if err != nil {
+		return nil, e.Wrap("GetLastPrice api.db.Select failed with an error: ", err)
+	}
+
+	if len(priceRow) == 1 {
+		return &priceRow[0], nil
+	}
+
+	return nil, err
+}
+
+func (api *SQLAPI) AddPrice(ctx context.Context, price PriceRow) error {
+	defer metrics.ObserveQuery("AddPrice", time.Now())
+
+	const query = `INSERT INTO link_price(priceid, linkid, price, discount, free) VALUES (:priceid, :linkid, :price, :discount, :free);`
+
+	if _, err := api.db.NamedExecContext(ctx, query, price); err != nil {
+		return e.Wrap("INSERT link price failed with an error: ", err)
+	}
+
+	return nil
+}
+
+func (api *SQLAPI) GetLinkItems(linkID string) ([]*ItemRow, error) {
+	defer metrics.ObserveQuery("GetLinkItems", time.Now())
+
+	itemRow := []*ItemRow{}
+
+	err := api.db.Select(&itemRow, "SELECT * FROM link_item WHERE linkid = $1;", linkID)
+	if err != nil {
+		return nil, e.Wrap("GetLinkItems api.db.Select failed with an error: ", err)
+	}
+
+	return itemRow, err
+}
+
+func (api *SQLAPI) AddLinkItem(ctx context.Context, item ItemRow) error {
+	defer metrics.ObserveQuery("AddLinkItem", time.Now())
+
+	const query = `INSERT INTO link_item(itemid, linkid, kind, externalid, title, position, status, comments) VALUES (:itemid, :linkid, :kind, :externalid, :title, :position, :status, :comments) ON CONFLICT DO NOTHING;`
+
+	if _, err := api.db.NamedExecContext(ctx, query, item); err != nil {
+		return e.Wrap("INSERT link item failed with an error: ", err)
+	}
+
+	return nil
+}
+
+func (api *SQLAPI) UpdateLinkItem(ctx context.Context, item ItemRow) error {
+	defer metrics.ObserveQuery("UpdateLinkItem", time.Now())
+
+	const query = `UPDATE link_item SET title = :title, position = :position, status = :status, comments = :comments WHERE itemid = :itemid;`
+
+	if _, err := api.db.NamedExecContext(ctx, query, item); err != nil {
+		return e.Wrap("UPDATE link item failed with an error: ", err)
+	}
+
+	return nil
+}
+
+// SetDelivery меняет способ доставки оповещений и отсчитывает период
+// сводки заново.
+func (api *SQLAPI) SetDelivery(ctx context.Context, userID int, delivery string, hour int) error {
+	defer metrics.ObserveQuery("SetDelivery", time.Now())
+
+	_, err := api.db.ExecContext(ctx, "UPDATE prj_user SET delivery = $1, digesthour = $2, lastdigest = now() WHERE userid = $3;", delivery, hour, userID)
+	if err != nil {
+		return e.Wrap("UPDATE prj_user delivery failed with an error: ", err)
+	}
+
+	return nil
+}
+
+func (api *SQLAPI) SetLastDigest(ctx context.Context, userID int, sent time.Time) error {
+	defer metrics.ObserveQuery("SetLastDigest", time.Now())
+
+	_, err := api.db.ExecContext(ctx, "UPDATE prj_user SET lastdigest = $1 WHERE userid = $2;", sent, userID)
+	if err != nil {
+		return e.Wrap("UPDATE prj_user last digest failed with an error: ", err)
+	}
+
+	return nil
+}
+
+func (api *SQLAPI) AddNotice(ctx context.Context, notice NoticeRow) error {
+	defer metrics.ObserveQuery("AddNotice", time.Now())
+
+	const query = `INSERT INTO pending_notice(noticeid, userid, chatid, linkid, text) VALUES (:noticeid, :userid, :chatid, :linkid, :text);`
+
+	if _, err := api.db.NamedExecContext(ctx, query, notice); err != nil {
+		return e.Wrap("INSERT pending notice failed with an error: ", err)
+	}
+
+	return nil
+}
+
+// GetPendingUsers возвращает пользователей, у которых есть отложенные оповещения.
+func (api *SQLAPI) GetPendingUsers() ([]*UserRow, error) {
+	defer metrics.ObserveQuery("GetPendingUsers", time.Now())
+
+	userRow := []*UserRow{}
+
+	err := api.db.Select(&userRow, "SELECT DISTINCT prj_user.* FROM prj_user JOIN pending_notice ON pending_notice.userid = prj_user.userid;")
+	if err != nil {
+		return nil, e.Wrap("GetPendingUsers api.db.Select failed with an error: ", err)
+	}
+
+	return userRow, err
+}
+
+// GetNotices возвращает отложенные оповещения пользователя, сгруппированные
+// по ссылкам.
+func (api *SQLAPI) GetNotices(userID int) ([]*NoticeRow, error) {
+	defer metrics.ObserveQuery("GetNotices", time.Now())
+
+	noticeRow := []*NoticeRow{}
+
+	err := api.db.Select(&noticeRow, "SELECT pending_notice.*, prj_link.link, prj_link.title FROM pending_notice JOIN prj_link ON prj_link.linkid = pending_notice.linkid WHERE pending_notice.userid = $1 ORDER BY pending_notice.linkid, pending_notice.created;", userID)
+	if err != nil {
+		return nil, e.Wrap("GetNotices api.db.Select failed with an error: ", err)
+	}
+
+	return noticeRow, err
+}
+
+// RemoveNotices удаляет отложенные оповещения, созданные не позже before.
+func (api *SQLAPI) RemoveNotices(ctx context.Context, userID int, before time.Time) error {
+	defer metrics.ObserveQuery("RemoveNotices", time.Now())
+
+	_, err := api.db.ExecContext(ctx, "DELETE FROM pending_notice WHERE userid = $1 AND created <= $2;", userID, before)
+	if err != nil {
+		return e.Wrap("DELETE pending notices failed with an error: ", err)
+	}
+
+	return nil
+}
+
+func (api *SQLAPI) SetTimezone(ctx context.Context, userID int, timezone string) error {
+	defer metrics.ObserveQuery("SetTimezone", time.Now())
+
+	_, err := api.db.ExecContext(ctx, "UPDATE prj_user SET timezone = $1 WHERE userid = $2;", timezone, userID)
+	if err != nil {
+		return e.Wrap("UPDATE prj_user timezone failed with an error: ", err)
+	}
+
+	return nil
+}
+
+// SetLang сохраняет язык сообщений пользователя. Пустая строка означает
+// выбор языка по настройкам Telegram.
+func (api *SQLAPI) SetLang(ctx context.Context, userID int, lang string) error {
+	defer metrics.ObserveQuery("SetLang", time.Now())
+
+	_, err := api.db.ExecContext(ctx, "UPDATE prj_user SET lang = $1 WHERE userid = $2;", lang, userID)
+	if err != nil {
+		return e.Wrap("UPDATE prj_user lang failed with an error: ", err)
+	}
+
+	return nil
+}
+
+// SetQuietHours сохраняет тихие часы в минутах от полуночи. Равные start
+// и end означают, что тихие часы отключены.
+func (api *SQLAPI) SetQuietHours(ctx context.Context, userID int, start, end int) error {
+	defer metrics.ObserveQuery("SetQuietHours", time.Now())
+
+	_, err := api.db.ExecContext(ctx, "UPDATE prj_user SET quietstart = $1, quietend = $2 WHERE userid = $3;", start, end, userID)
+	if err != nil {
+		return e.Wrap("UPDATE prj_user quiet hours failed with an error: ", err)
+	}
+
+	return nil
+}
+
+// GetRefByID возвращает подписку вместе со ссылкой, на которую она оформлена.
+func (api *SQLAPI) GetRefByID(refID string) (*RefRow, error) {
+	defer metrics.ObserveQuery("GetRefByID", time.Now())
+
+	refRow := []RefRow{}
+
+	err := api.db.Select(&refRow, "SELECT ref_link_user.*, prj_link.link FROM ref_link_user JOIN prj_link ON prj_link.linkid = ref_link_user.linkid WHERE ref_link_user.refid = $1;", refID)
+	if err != nil {
+		return nil, e.Wrap("GetRefByID api.db.Select failed with an error: ", err)
+	}
+
+	if len(refRow) == 1 {
+		return &refRow[0], nil
+	}
+
+	return nil, err
+}
+
+// GetUserRefs возвращает подписки пользователя вместе со ссылками в порядке
+// их добавления.
+func (api *SQLAPI) GetUserRefs(userID int) ([]*RefRow, error) {
+	defer metrics.ObserveQuery("GetUserRefs", time.Now())
+
+	refRow := []*RefRow{}
+
+	err := api.db.Select(&refRow, "SELECT ref_link_user.*, prj_link.link, prj_link.kind, prj_link.title FROM ref_link_user JOIN prj_link ON prj_link.linkid = ref_link_user.linkid WHERE ref_link_user.userid = $1 ORDER BY ref_link_user.created;", userID)
+	if err != nil {
+		return nil, e.Wrap("GetUserRefs api.db.Select failed with an error: ", err)
+	}
+
+	return refRow, err
+}
+
+// UpdateRefFilters сохраняет выбранные типы оповещений и ключевые слова подписки.
+func (api *SQLAPI) UpdateRefFilters(ctx context.Context, ref RefRow) error {
+	defer metrics.ObserveQuery("UpdateRefFilters", time.Now())
+
+	const query = `UPDATE ref_link_user SET chapteralert = :chapteralert, pricealert = :pricealert, statusalert = :statusalert, blogalert = :blogalert, commentalert = :commentalert, annotationalert = :annotationalert, include = :include, exclude = :exclude WHERE refid = :refid;`
+
+	if _, err := api.db.NamedExecContext(ctx, query, ref); err != nil {
+		return e.Wrap("UPDATE ref_link_user filters failed with an error: ", err)
+	}
+
+	return nil
+}
+
+func (api *SQLAPI) SetPaused(ctx context.Context, userID int, linkID string, paused bool) error {
+	defer metrics.ObserveQuery("SetPaused", time.Now())
+
+	_, err := api.db.ExecContext(ctx, "UPDATE ref_link_user SET paused = $1 WHERE userid = $2 AND linkid = $3;", paused, userID, linkID)
+	if err != nil {
+		return e.Wrap("UPDATE ref_link_user paused failed with an error: ", err)
+	}
+
+	return nil
+}
+
+func (api *SQLAPI) SetSnooze(ctx context.Context, userID int, linkID string, until time.Time) error {
+	defer metrics.ObserveQuery("SetSnooze", time.Now())
+
+	_, err := api.db.ExecContext(ctx, "UPDATE ref_link_user SET snoozeuntil = $1 WHERE userid = $2 AND linkid = $3;", until, userID, linkID)
+	if err != nil {
+		return e.Wrap("UPDATE ref_link_user snooze failed with an error: ", err)
+	}
+
+	return nil
+}
+
+func (api *SQLAPI) SetUserSnooze(ctx context.Context, userID int, until time.Time) error {
+	defer metrics.ObserveQuery("SetUserSnooze", time.Now())
+
+	_, err := api.db.ExecContext(ctx, "UPDATE prj_user SET snoozeuntil = $1 WHERE userid = $2;", until, userID)
+	if err != nil {
+		return e.Wrap("UPDATE prj_user snooze failed with an error: ", err)
+	}
+
+	return nil
+}
+
+// RemoveOrphanLinks удаляет ссылки, на которые никто не подписан, вместе
+// с их историей цен и элементами, и возвращает число удаленных ссылок.
+func (api *SQLAPI) RemoveOrphanLinks(ctx context.Context) (int64, error) {
+	defer metrics.ObserveQuery("RemoveOrphanLinks", time.Now())
+
+	res, err := api.db.ExecContext(ctx, "DELETE FROM prj_link WHERE NOT EXISTS (SELECT 1 FROM ref_link_user WHERE ref_link_user.linkid = prj_link.linkid);")
+	if err != nil {
+		return 0, e.Wrap("DELETE orphan links failed with an error: ", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, e.Wrap("orphan links rows affected failed with an error: ", err)
+	}
+
+	return n, nil
+}
+
+// SetChatID меняет чат, в который приходят оповещения пользователя.
+func (api *SQLAPI) SetChatID(ctx context.Context, userID int, chatID int64) error {
+	defer metrics.ObserveQuery("SetChatID", time.Now())
+
+	_, err := api.db.ExecContext(ctx, "UPDATE prj_user SET chatid = $1 WHERE userid = $2;", chatID, userID)
+	if err != nil {
+		return e.Wrap("UPDATE prj_user chat id failed with an error: ", err)
+	}
+
+	return nil
+}
+
+// MigrateUser переносит владельца подписок на новый идентификатор вместе с
+// подписками и отложенными оповещениями. Оповещения, которые приходили в
+// чат самого владельца, переходят в новый чат, а в канал — остаются.
+func (api *SQLAPI) MigrateUser(ctx context.Context, fromID, toID int64) error {
+	defer metrics.ObserveQuery("MigrateUser", time.Now())
+
+	_, err := api.db.ExecContext(ctx, "UPDATE prj_user SET userid = $1, chatid = CASE WHEN chatid = $2 THEN $1 ELSE chatid END WHERE userid = $2;", toID, fromID)
+	if err != nil {
+		return e.Wrap("UPDATE prj_user migrate failed with an error: ", err)
+	}
+
+	return nil
+}
+
+// SetBanned блокирует пользователя или снимает блокировку.
+func (api *SQLAPI) SetBanned(ctx context.Context, userID int, banned bool) error {
+	defer metrics.ObserveQuery("SetBanned", time.Now())
+
+	_, err := api.db.ExecContext(ctx, "UPDATE prj_user SET banned = $1 WHERE userid = $2;", banned, userID)
+	if err != nil {
+		return e.Wrap("UPDATE prj_user banned failed with an error: ", err)
+	}
+
+	return nil
+}
+
+// SetBlocked отмечает, что пользователь заблокировал бота или удалил его
+// из группы, либо снимает отметку.
+func (api *SQLAPI) SetBlocked(ctx context.Context, userID int, blocked bool) error {
+	defer metrics.ObserveQuery("SetBlocked", time.Now())
+
+	_, err := api.db.ExecContext(ctx, "UPDATE prj_user SET blocked = $1 WHERE userid = $2;", blocked, userID)
+	if err != nil {
+		return e.Wrap("UPDATE prj_user blocked failed with an error: ", err)
+	}
+
+	return nil
+}
+
+// ResetChatID возвращает оповещения, которые приходили в чат chatID, в чаты
+// владельцев подписок и сообщает, скольких владельцев это затронуло.
+func (api *SQLAPI) ResetChatID(ctx context.Context, chatID int64) (int64, error) {
+	defer metrics.ObserveQuery("ResetChatID", time.Now())
+
+	res, err := api.db.ExecContext(ctx, "UPDATE prj_user SET chatid = userid WHERE chatid = $1 AND userid <> $1;", chatID)
+	if err != nil {
+		return 0, e.Wrap("UPDATE prj_user reset chat id failed with an error: ", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, e.Wrap("reset chat id rows affected failed with an error: ", err)
+	}
+
+	return n, nil
+}
+
+// GetActiveUsers возвращает пользователей, которых не заблокировал
+// администратор и которые сами не заблокировали бота.
+func (api *SQLAPI) GetActiveUsers() ([]*UserRow, error) {
+	defer metrics.ObserveQuery("GetActiveUsers", time.Now())
+
+	userRow := []*UserRow{}
+
+	err := api.db.Select(&userRow, "SELECT * FROM prj_user WHERE NOT banned AND NOT blocked ORDER BY userid;")
+	if err != nil {
+		return nil, e.Wrap("GetActiveUsers api.db.Select failed with an error: ", err)
+	}
+
+	return userRow, err
+}
+
+// GetStats считает пользователей, ссылки и подписки.
+func (api *SQLAPI) GetStats() (*StatsRow, error) {
+	defer metrics.ObserveQuery("GetStats", time.Now())
+
+	statsRow := StatsRow{}
+
+	err := api.db.Get(&statsRow, "SELECT (SELECT count(*) FROM prj_user) AS users, (SELECT count(*) FROM prj_user WHERE banned) AS banned, (SELECT count(*) FROM prj_user WHERE blocked) AS blocked, (SELECT count(*) FROM prj_link) AS links, (SELECT count(*) FROM ref_link_user) AS subscriptions;")
+	if err != nil {
+		return nil, e.Wrap("GetStats api.db.Get failed with an error: ", err)
+	}
+
+	return &statsRow, nil
+}
+
+// GetLastUpdateID возвращает идентификатор обновления Telegram, обработанного
+// последним, но не раньше after, или 0, если таких обновлений нет.
+// Идентификаторы Telegram могут начаться заново, поэтому берется последнее
+// по времени обновление, а не наибольший идентификатор.
+func (api *SQLAPI) GetLastUpdateID(ctx context.Context, after time.Time) (int, error) {
+	defer metrics.ObserveQuery("GetLastUpdateID", time.Now())
+
+	updateID := 0
+
+	err := api.db.GetContext(ctx, &updateID, "SELECT COALESCE((SELECT updateid FROM processed_update WHERE processed >= $1 ORDER BY processed DESC, updateid DESC LIMIT 1), 0);", after)
+	if err != nil {
+		return 0, e.Wrap("GetLastUpdateID api.db.Get failed with an error: ", err)
+	}
+
+	return updateID, nil
+}
+
+// ClaimUpdate отмечает обновление обработанным. Возвращает false, если
+// обновление уже было отмечено раньше.
+func (api *SQLAPI) ClaimUpdate(ctx context.Context, updateID int) (bool, error) {
+	defer metrics.ObserveQuery("ClaimUpdate", time.Now())
+
+	res, err := api.db.ExecContext(ctx, "INSERT INTO processed_update (updateid, processed) VALUES ($1, now()) ON CONFLICT DO NOTHING;", updateID)
+	if err != nil {
+		return false, e.Wrap("INSERT processed update failed with an error: ", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, e.Wrap("processed update rows affected failed with an error: ", err)
+	}
+
+	return n == 1, nil
+}
+
+// PruneUpdates удаляет отметки об обновлениях, обработанных раньше before.
+func (api *SQLAPI) PruneUpdates(ctx context.Context, before time.Time) error {
+	defer metrics.ObserveQuery("PruneUpdates", time.Now())
+
+	_, err := api.db.ExecContext(ctx, "DELETE FROM processed_update WHERE processed < $1;", before)
+	if err != nil {
+		return e.Wrap("DELETE processed updates failed with an error: ", err)
+	}
+
+	return nil
+}
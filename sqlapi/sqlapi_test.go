@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/jmoiron/sqlx"
@@ -274,9 +275,9 @@ func Test_GetRefByIDLinkUser(t *testing.T) {
 }
 
 func Test_GetLinksUser(t *testing.T) {
-	columns := []string{"refid", "userid", "linkid"}
+	columns := []string{"linkid", "link", "title", "kind", "volumes", "checked"}
 
-	const expectedQuery = "SELECT prj_link.linkid, prj_link.link FROM ref_link_user JOIN prj_link ON prj_link.linkid = ref_link_user.linkid WHERE ref_link_user.userid = (.+);"
+	const expectedQuery = "SELECT prj_link.linkid, prj_link.link, prj_link.title, prj_link.kind, prj_link.volumes, prj_link.checked, prj_link.lastcheck, ref_link_user.paused, ref_link_user.snoozeuntil FROM ref_link_user JOIN prj_link ON prj_link.linkid = ref_link_user.linkid WHERE ref_link_user.userid = (.+);"
 
 	tests := []struct {
 		name    string
@@ -288,7 +289,7 @@ func Test_GetLinksUser(t *testing.T) {
 			prepare: func(mock sqlmock.Sqlmock) {
 				mock.ExpectQuery(expectedQuery).
 					WithArgs(123).
-					WillReturnRows(sqlmock.NewRows(columns).FromCSVString("1,1"))
+					WillReturnRows(sqlmock.NewRows(columns).FromCSVString("1,1,1,series,3,true"))
 			},
 			wantErr: false,
 		},
@@ -297,7 +298,7 @@ func Test_GetLinksUser(t *testing.T) {
 			prepare: func(mock sqlmock.Sqlmock) {
 				mock.ExpectQuery(expectedQuery).
 					WithArgs(123).
-					WillReturnRows(sqlmock.NewRows(columns).FromCSVString("0,0,0"))
+					WillReturnRows(sqlmock.NewRows(columns).FromCSVString("0,0,0,book,0,false"))
 			},
 			wantErr: false,
 		},
@@ -399,8 +400,9 @@ func Test_AddLink(t *testing.T) {
 	linkR := LinkRow{
 		LinkID: "linkID",
 		Link:   "link",
+		Kind:   "book",
 	}
-	const expectedQuery = `INSERT INTO prj_link\(linkid, link\) VALUES (.+) ON CONFLICT DO NOTHING;`
+	const expectedQuery = `INSERT INTO prj_link\(linkid, link, kind\) VALUES (.+) ON CONFLICT DO NOTHING;`
 
 	tests := []struct {
 		name    string
@@ -410,14 +412,14 @@ func Test_AddLink(t *testing.T) {
 		{
 			name: "success add new link",
 			prepare: func(mock sqlmock.Sqlmock) {
-				mock.ExpectExec(expectedQuery).WithArgs(linkR.LinkID, linkR.Link).WillReturnResult(sqlmock.NewResult(1, 0))
+				mock.ExpectExec(expectedQuery).WithArgs(linkR.LinkID, linkR.Link, linkR.Kind).WillReturnResult(sqlmock.NewResult(1, 0))
 			},
 			wantErr: false,
 		},
 		{
 			name: "error on add new link",
 			prepare: func(mock sqlmock.Sqlmock) {
-				mock.ExpectExec(expectedQuery).WithArgs(linkR.LinkID, linkR.Link).WillReturnError(errors.New("some error"))
+				mock.ExpectExec(expectedQuery).WithArgs(linkR.LinkID, linkR.Link, linkR.Kind).WillReturnError(errors.New("some error"))
 			},
 			wantErr: true,
 		},
@@ -435,7 +437,7 @@ func Test_AddLink(t *testing.T) {
 			tt.prepare(mock)
 
 			api := NewSQLAPI(db)
-			if err := api.AddLink(ctx, linkR.Link, linkR.LinkID); (err != nil) != tt.wantErr {
+			if err := api.AddLink(ctx, linkR.Link, linkR.LinkID, linkR.Kind); (err != nil) != tt.wantErr {
 				t.Errorf("AddLink() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
@@ -601,3 +603,2025 @@ func Test_RemoveRefByUserIDLinkID(t *testing.T) {
 		})
 	}
 }
+
+func Test_GetAllLinks(t *testing.T) {
+	columns := []string{"linkid", "link", "title", "chapters", "checked", "status", "kind", "volumes"}
+
+	const expectedQuery = "SELECT DISTINCT prj_link.\\* FROM prj_link JOIN ref_link_user ON ref_link_user.linkid = prj_link.linkid JOIN prj_user ON prj_user.userid = ref_link_user.userid WHERE ref_link_user.paused = false AND (.+);"
+
+	tests := []struct {
+		name    string
+		prepare func(mock sqlmock.Sqlmock)
+		wantErr bool
+	}{
+		{
+			name: "get links",
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(expectedQuery).
+					WillReturnRows(sqlmock.NewRows(columns).FromCSVString("1,1,1,1,true,completed,book,0"))
+			},
+			wantErr: false,
+		},
+		{
+			name: "get links error",
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(expectedQuery).
+					WillReturnError(errors.New("some error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			baseDB, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+			}
+			db := sqlx.NewDb(baseDB, "postgres")
+			defer db.Close()
+
+			tt.prepare(mock)
+
+			api := NewSQLAPI(db)
+
+			_, err = api.GetAllLinks()
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetAllLinks() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("GetAllLinks() there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func Test_UpdateLink(t *testing.T) {
+	ctx := context.Background()
+	linkR := LinkRow{
+		LinkID:     "linkID",
+		Link:       "link",
+		Title:      "title",
+		Chapters:   10,
+		Checked:    true,
+		Status:     "completed",
+		Volumes:    0,
+		Annotation: "annotation",
+	}
+
+	const expectedQuery = `UPDATE prj_link SET title = (.+), chapters = (.+), checked = (.+), status = (.+), volumes = (.+), annotation = (.+), lastcheck = now\(\) WHERE linkid = (.+);`
+
+	tests := []struct {
+		name    string
+		prepare func(mock sqlmock.Sqlmock)
+		wantErr bool
+	}{
+		{
+			name: "success update link",
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(expectedQuery).WithArgs(linkR.Title, linkR.Chapters, linkR.Checked, linkR.Status, linkR.Volumes, linkR.Annotation, linkR.LinkID).WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+			wantErr: false,
+		},
+		{
+			name: "error on update link",
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(expectedQuery).WithArgs(linkR.Title, linkR.Chapters, linkR.Checked, linkR.Status, linkR.Volumes, linkR.Annotation, linkR.LinkID).WillReturnError(errors.New("some error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			baseDB, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+			}
+			db := sqlx.NewDb(baseDB, "postgres")
+			defer db.Close()
+
+			tt.prepare(mock)
+
+			api := NewSQLAPI(db)
+			if err := api.UpdateLink(ctx, linkR); (err != nil) != tt.wantErr {
+				t.Errorf("UpdateLink() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("UpdateLink() there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func Test_GetSubscribers(t *testing.T) {
+	columns := []string{"userid", "chatid", "pricealert", "pricebelow", "chapteralert", "statusalert", "delivery", "timezone", "quietstart", "quietend", "lang"}
+
+	const expectedQuery = "SELECT (.+) FROM ref_link_user JOIN prj_user ON prj_user.userid = ref_link_user.userid WHERE ref_link_user.linkid = (.+) AND NOT prj_user.banned AND NOT prj_user.blocked;"
+
+	tests := []struct {
+		name    string
+		prepare func(mock sqlmock.Sqlmock)
+		wantErr bool
+	}{
+		{
+			name: "get subscribers",
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(expectedQuery).
+					WithArgs("linkid").
+					WillReturnRows(sqlmock.NewRows(columns).FromCSVString("1,1,true,100,false,true,daily,Europe/Moscow,1380,480,en"))
+			},
+			wantErr: false,
+		},
+		{
+			name: "get subscribers error",
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(expectedQuery).
+					WithArgs("linkid").
+					WillReturnRows(sqlmock.NewRows(columns).FromCSVString("0,1"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			baseDB, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+			}
+			db := sqlx.NewDb(baseDB, "postgres")
+			defer db.Close()
+
+			tt.prepare(mock)
+
+			api := NewSQLAPI(db)
+
+			_, err = api.GetSubscribers("linkid")
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetSubscribers() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("GetSubscribers() there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func Test_SetPriceAlert(t *testing.T) {
+	ctx := context.Background()
+
+	const expectedQuery = `UPDATE ref_link_user SET pricealert = (.+), pricebelow = (.+) WHERE userid = (.+) AND linkid = (.+);`
+
+	tests := []struct {
+		name    string
+		prepare func(mock sqlmock.Sqlmock)
+		wantErr bool
+	}{
+		{
+			name: "set price alert",
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(expectedQuery).WithArgs(true, 100, 123, "linkid").WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+			wantErr: false,
+		},
+		{
+			name: "set price alert err",
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(expectedQuery).WithArgs(true, 100, 123, "linkid").WillReturnError(errors.New("some error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			baseDB, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+			}
+			db := sqlx.NewDb(baseDB, "postgres")
+			defer db.Close()
+
+			tt.prepare(mock)
+
+			api := NewSQLAPI(db)
+			if err := api.SetPriceAlert(ctx, 123, "linkid", true, 100); (err != nil) != tt.wantErr {
+				t.Errorf("SetPriceAlert() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("SetPriceAlert() there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func Test_SetChapterAlert(t *testing.T) {
+	ctx := context.Background()
+
+	const expectedQuery = `UPDATE ref_link_user SET chapteralert = (.+) WHERE userid = (.+) AND linkid = (.+);`
+
+	tests := []struct {
+		name    string
+		prepare func(mock sqlmock.Sqlmock)
+		wantErr bool
+	}{
+		{
+			name: "set chapter alert",
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(expectedQuery).WithArgs(false, 123, "linkid").WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+			wantErr: false,
+		},
+		{
+			name: "set chapter alert err",
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(expectedQuery).WithArgs(false, 123, "linkid").WillReturnError(errors.New("some error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			baseDB, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+			}
+			db := sqlx.NewDb(baseDB, "postgres")
+			defer db.Close()
+
+			tt.prepare(mock)
+
+			api := NewSQLAPI(db)
+			if err := api.SetChapterAlert(ctx, 123, "linkid", false); (err != nil) != tt.wantErr {
+				t.Errorf("SetChapterAlert() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("SetChapterAlert() there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func Test_SetStatusAlert(t *testing.T) {
+	ctx := context.Background()
+
+	const expectedQuery = `UPDATE ref_link_user SET statusalert = (.+) WHERE userid = (.+) AND linkid = (.+);`
+
+	tests := []struct {
+		name    string
+		prepare func(mock sqlmock.Sqlmock)
+		wantErr bool
+	}{
+		{
+			name: "set status alert",
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(expectedQuery).WithArgs(true, 123, "linkid").WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+			wantErr: false,
+		},
+		{
+			name: "set status alert err",
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(expectedQuery).WithArgs(true, 123, "linkid").WillReturnError(errors.New("some error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			baseDB, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+			}
+			db := sqlx.NewDb(baseDB, "postgres")
+			defer db.Close()
+
+			tt.prepare(mock)
+
+			api := NewSQLAPI(db)
+			if err := api.SetStatusAlert(ctx, 123, "linkid", true); (err != nil) != tt.wantErr {
+				t.Errorf("SetStatusAlert() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("SetStatusAlert() there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func Test_GetLastPrice(t *testing.T) {
+	columns := []string{"priceid", "linkid", "price", "discount", "free", "created"}
+
+	const expectedQuery = "SELECT (.+) FROM link_price WHERE linkid = (.+) ORDER BY created DESC LIMIT 1;"
+
+	tests := []struct {
+		name    string
+		prepare func(mock sqlmock.Sqlmock)
+		wantErr bool
+	}{
+		{
+			name: "get price",
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(expectedQuery).
+					WithArgs("linkid").
+					WillReturnRows(sqlmock.NewRows(columns).AddRow("1", "1", 100, 10, false, time.Now()))
+			},
+			wantErr: false,
+		},
+		{
+			name: "get price nil",
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(expectedQuery).
+					WithArgs("linkid").
+					WillReturnRows(sqlmock.NewRows(columns))
+			},
+			wantErr: false,
+		},
+		{
+			name: "get price error",
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(expectedQuery).
+					WithArgs("linkid").
+					WillReturnRows(sqlmock.NewRows(columns).FromCSVString("0"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			baseDB, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+			}
+			db := sqlx.NewDb(baseDB, "postgres")
+			defer db.Close()
+
+			tt.prepare(mock)
+
+			api := NewSQLAPI(db)
+
+			_, err = api.GetLastPrice("linkid")
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetLastPrice() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("GetLastPrice() there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func Test_AddPrice(t *testing.T) {
+	ctx := context.Background()
+	priceR := PriceRow{
+		PriceID:  "priceID",
+		LinkID:   "linkID",
+		Price:    100,
+		Discount: 20,
+		Free:     false,
+	}
+
+	const expectedQuery = `INSERT INTO link_price\(priceid, linkid, price, discount, free\) VALUES (.+);`
+
+	tests := []struct {
+		name    string
+		prepare func(mock sqlmock.Sqlmock)
+		wantErr bool
+	}{
+		{
+			name: "success add price",
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(expectedQuery).WithArgs(priceR.PriceID, priceR.LinkID, priceR.Price, priceR.Discount, priceR.Free).WillReturnResult(sqlmock.NewResult(1, 0))
+			},
+			wantErr: false,
+		},
+		{
+			name: "error on add price",
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(expectedQuery).WithArgs(priceR.PriceID, priceR.LinkID, priceR.Price, priceR.Discount, priceR.Free).WillReturnError(errors.New("some error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			baseDB, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+			}
+			db := sqlx.NewDb(baseDB, "postgres")
+			defer db.Close()
+
+			tt.prepare(mock)
+
+			api := NewSQLAPI(db)
+			if err := api.AddPrice(ctx, priceR); (err != nil) != tt.wantErr {
+				t.Errorf("AddPrice() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("AddPrice() there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func Test_GetLinkItems(t *testing.T) {
+	columns := []string{"itemid", "linkid", "kind", "externalid", "title", "position", "status"}
+
+	const expectedQuery = "SELECT (.+) FROM link_item WHERE linkid = (.+);"
+
+	tests := []struct {
+		name    string
+		prepare func(mock sqlmock.Sqlmock)
+		wantErr bool
+	}{
+		{
+			name: "get items",
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(expectedQuery).
+					WithArgs("linkid").
+					WillReturnRows(sqlmock.NewRows(columns).FromCSVString("1,1,work,101,title,1,completed"))
+			},
+			wantErr: false,
+		},
+		{
+			name: "get items error",
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(expectedQuery).
+					WithArgs("linkid").
+					WillReturnRows(sqlmock.NewRows(columns).FromCSVString("0"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			baseDB, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+			}
+			db := sqlx.NewDb(baseDB, "postgres")
+			defer db.Close()
+
+			tt.prepare(mock)
+
+			api := NewSQLAPI(db)
+
+			_, err = api.GetLinkItems("linkid")
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetLinkItems() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("GetLinkItems() there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func Test_AddLinkItem(t *testing.T) {
+	ctx := context.Background()
+	itemR := ItemRow{
+		ItemID:     "itemID",
+		LinkID:     "linkID",
+		Kind:       "work",
+		ExternalID: "101",
+		Title:      "title",
+		Position:   1,
+		Status:     "completed",
+		Comments:   0,
+	}
+
+	const expectedQuery = `INSERT INTO link_item\(itemid, linkid, kind, externalid, title, position, status, comments\) VALUES (.+) ON CONFLICT DO NOTHING;`
+
+	tests := []struct {
+		name    string
+		prepare func(mock sqlmock.Sqlmock)
+		wantErr bool
+	}{
+		{
+			name: "success add item",
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(expectedQuery).WithArgs(itemR.ItemID, itemR.LinkID, itemR.Kind, itemR.ExternalID, itemR.Title, itemR.Position, itemR.Status, itemR.Comments).WillReturnResult(sqlmock.NewResult(1, 0))
+			},
+			wantErr: false,
+		},
+		{
+			name: "error on add item",
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(expectedQuery).WithArgs(itemR.ItemID, itemR.LinkID, itemR.Kind, itemR.ExternalID, itemR.Title, itemR.Position, itemR.Status, itemR.Comments).WillReturnError(errors.New("some error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			baseDB, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+			}
+			db := sqlx.NewDb(baseDB, "postgres")
+			defer db.Close()
+
+			tt.prepare(mock)
+
+			api := NewSQLAPI(db)
+			if err := api.AddLinkItem(ctx, itemR); (err != nil) != tt.wantErr {
+				t.Errorf("AddLinkItem() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("AddLinkItem() there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func Test_UpdateLinkItem(t *testing.T) {
+	ctx := context.Background()
+	itemR := ItemRow{
+		ItemID:   "itemID",
+		Title:    "title",
+		Position: 2,
+		Status:   "frozen",
+		Comments: 4,
+	}
+
+	const expectedQuery = `UPDATE link_item SET title = (.+), position = (.+), status = (.+), comments = (.+) WHERE itemid = (.+);`
+
+	tests := []struct {
+		name    string
+		prepare func(mock sqlmock.Sqlmock)
+		wantErr bool
+	}{
+		{
+			name: "success update item",
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(expectedQuery).WithArgs(itemR.Title, itemR.Position, itemR.Status, itemR.Comments, itemR.ItemID).WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+			wantErr: false,
+		},
+		{
+			name: "error on update item",
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(expectedQuery).WithArgs(itemR.Title, itemR.Position, itemR.Status, itemR.Comments, itemR.ItemID).WillReturnError(errors.New("some error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			baseDB, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+			}
+			db := sqlx.NewDb(baseDB, "postgres")
+			defer db.Close()
+
+			tt.prepare(mock)
+
+			api := NewSQLAPI(db)
+			if err := api.UpdateLinkItem(ctx, itemR); (err != nil) != tt.wantErr {
+				t.Errorf("UpdateLinkItem() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("UpdateLinkItem() there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func Test_SetDelivery(t *testing.T) {
+	ctx := context.Background()
+
+	const expectedQuery = `UPDATE prj_user SET delivery = (.+), digesthour = (.+), lastdigest = now\(\) WHERE userid = (.+);`
+
+	tests := []struct {
+		name    string
+		prepare func(mock sqlmock.Sqlmock)
+		wantErr bool
+	}{
+		{
+			name: "success SetDelivery",
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(expectedQuery).WithArgs("daily", 9, 123).WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+			wantErr: false,
+		},
+		{
+			name: "error on SetDelivery",
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(expectedQuery).WithArgs("daily", 9, 123).WillReturnError(errors.New("some error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			baseDB, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+			}
+			db := sqlx.NewDb(baseDB, "postgres")
+			defer db.Close()
+
+			tt.prepare(mock)
+
+			api := NewSQLAPI(db)
+			if err := api.SetDelivery(ctx, 123, "daily", 9); (err != nil) != tt.wantErr {
+				t.Errorf("SetDelivery() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("SetDelivery() there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func Test_SetLastDigest(t *testing.T) {
+	ctx := context.Background()
+	sent := time.Date(2023, 5, 1, 9, 0, 0, 0, time.UTC)
+
+	const expectedQuery = `UPDATE prj_user SET lastdigest = (.+) WHERE userid = (.+);`
+
+	tests := []struct {
+		name    string
+		prepare func(mock sqlmock.Sqlmock)
+		wantErr bool
+	}{
+		{
+			name: "success SetLastDigest",
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(expectedQuery).WithArgs(sent, 123).WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+			wantErr: false,
+		},
+		{
+			name: "error on SetLastDigest",
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(expectedQuery).WithArgs(sent, 123).WillReturnError(errors.New("some error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			baseDB, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+			}
+			db := sqlx.NewDb(baseDB, "postgres")
+			defer db.Close()
+
+			tt.prepare(mock)
+
+			api := NewSQLAPI(db)
+			if err := api.SetLastDigest(ctx, 123, sent); (err != nil) != tt.wantErr {
+				t.Errorf("SetLastDigest() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("SetLastDigest() there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func Test_AddNotice(t *testing.T) {
+	ctx := context.Background()
+	noticeR := NoticeRow{
+		NoticeID: "noticeID",
+		UserID:   123,
+		ChatID:   456,
+		LinkID:   "linkID",
+		Text:     "text",
+	}
+
+	const expectedQuery = `INSERT INTO pending_notice\(noticeid, userid, chatid, linkid, text\) VALUES (.+);`
+
+	tests := []struct {
+		name    string
+		prepare func(mock sqlmock.Sqlmock)
+		wantErr bool
+	}{
+		{
+			name: "success AddNotice",
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(expectedQuery).WithArgs(noticeR.NoticeID, noticeR.UserID, noticeR.ChatID, noticeR.LinkID, noticeR.Text).WillReturnResult(sqlmock.NewResult(1, 0))
+			},
+			wantErr: false,
+		},
+		{
+			name: "error on AddNotice",
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(expectedQuery).WithArgs(noticeR.NoticeID, noticeR.UserID, noticeR.ChatID, noticeR.LinkID, noticeR.Text).WillReturnError(errors.New("some error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			baseDB, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+			}
+			db := sqlx.NewDb(baseDB, "postgres")
+			defer db.Close()
+
+			tt.prepare(mock)
+
+			api := NewSQLAPI(db)
+			if err := api.AddNotice(ctx, noticeR); (err != nil) != tt.wantErr {
+				t.Errorf("AddNotice() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("AddNotice() there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func Test_RemoveNotices(t *testing.T) {
+	ctx := context.Background()
+	before := time.Date(2023, 5, 1, 9, 0, 0, 0, time.UTC)
+
+	const expectedQuery = `DELETE FROM pending_notice WHERE userid = (.+) AND created <= (.+);`
+
+	tests := []struct {
+		name    string
+		prepare func(mock sqlmock.Sqlmock)
+		wantErr bool
+	}{
+		{
+			name: "success RemoveNotices",
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(expectedQuery).WithArgs(123, before).WillReturnResult(sqlmock.NewResult(0, 2))
+			},
+			wantErr: false,
+		},
+		{
+			name: "error on RemoveNotices",
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(expectedQuery).WithArgs(123, before).WillReturnError(errors.New("some error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			baseDB, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+			}
+			db := sqlx.NewDb(baseDB, "postgres")
+			defer db.Close()
+
+			tt.prepare(mock)
+
+			api := NewSQLAPI(db)
+			if err := api.RemoveNotices(ctx, 123, before); (err != nil) != tt.wantErr {
+				t.Errorf("RemoveNotices() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("RemoveNotices() there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func Test_GetPendingUsers(t *testing.T) {
+	columns := []string{"userid", "nameuser", "chatid", "delivery", "digesthour"}
+
+	const expectedQuery = "SELECT DISTINCT prj_user.\\* FROM prj_user JOIN pending_notice ON pending_notice.userid = prj_user.userid;"
+
+	tests := []struct {
+		name    string
+		prepare func(mock sqlmock.Sqlmock)
+		wantErr bool
+	}{
+		{
+			name: "success GetPendingUsers",
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(expectedQuery).
+					WillReturnRows(sqlmock.NewRows(columns).FromCSVString("1,name,1,daily,9"))
+			},
+			wantErr: false,
+		},
+		{
+			name: "error on GetPendingUsers",
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(expectedQuery).
+					WillReturnRows(sqlmock.NewRows(columns).FromCSVString("0,1"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			baseDB, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+			}
+			db := sqlx.NewDb(baseDB, "postgres")
+			defer db.Close()
+
+			tt.prepare(mock)
+
+			api := NewSQLAPI(db)
+
+			_, err = api.GetPendingUsers()
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetPendingUsers() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("GetPendingUsers() there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func Test_GetNotices(t *testing.T) {
+	columns := []string{"noticeid", "userid", "chatid", "linkid", "text", "link", "title"}
+
+	const expectedQuery = "SELECT (.+) FROM pending_notice JOIN prj_link ON prj_link.linkid = pending_notice.linkid WHERE pending_notice.userid = (.+) ORDER BY (.+);"
+
+	tests := []struct {
+		name    string
+		prepare func(mock sqlmock.Sqlmock)
+		wantErr bool
+	}{
+		{
+			name: "success GetNotices",
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(expectedQuery).
+					WithArgs(123).
+					WillReturnRows(sqlmock.NewRows(columns).FromCSVString("id,1,1,linkid,text,link,title"))
+			},
+			wantErr: false,
+		},
+		{
+			name: "error on GetNotices",
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(expectedQuery).
+					WithArgs(123).
+					WillReturnRows(sqlmock.NewRows(columns).FromCSVString("0,1"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			baseDB, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+			}
+			db := sqlx.NewDb(baseDB, "postgres")
+			defer db.Close()
+
+			tt.prepare(mock)
+
+			api := NewSQLAPI(db)
+
+			_, err = api.GetNotices(123)
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetNotices() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("GetNotices() there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func Test_SetTimezone(t *testing.T) {
+	ctx := context.Background()
+
+	const expectedQuery = `UPDATE prj_user SET timezone = (.+) WHERE userid = (.+);`
+
+	tests := []struct {
+		name    string
+		prepare func(mock sqlmock.Sqlmock)
+		wantErr bool
+	}{
+		{
+			name: "success SetTimezone",
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(expectedQuery).WithArgs("Europe/Moscow", 123).WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+			wantErr: false,
+		},
+		{
+			name: "error on SetTimezone",
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(expectedQuery).WithArgs("Europe/Moscow", 123).WillReturnError(errors.New("some error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			baseDB, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+			}
+			db := sqlx.NewDb(baseDB, "postgres")
+			defer db.Close()
+
+			tt.prepare(mock)
+
+			api := NewSQLAPI(db)
+			if err := api.SetTimezone(ctx, 123, "Europe/Moscow"); (err != nil) != tt.wantErr {
+				t.Errorf("SetTimezone() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("SetTimezone() there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func Test_SetLang(t *testing.T) {
+	ctx := context.Background()
+
+	const expectedQuery = `UPDATE prj_user SET lang = (.+) WHERE userid = (.+);`
+
+	tests := []struct {
+		name    string
+		prepare func(mock sqlmock.Sqlmock)
+		wantErr bool
+	}{
+		{
+			name: "success SetLang",
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(expectedQuery).WithArgs("en", 123).WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+			wantErr: false,
+		},
+		{
+			name: "error on SetLang",
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(expectedQuery).WithArgs("en", 123).WillReturnError(errors.New("some error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			baseDB, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+			}
+			db := sqlx.NewDb(baseDB, "postgres")
+			defer db.Close()
+
+			tt.prepare(mock)
+
+			api := NewSQLAPI(db)
+			if err := api.SetLang(ctx, 123, "en"); (err != nil) != tt.wantErr {
+				t.Errorf("SetLang() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("SetLang() there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func Test_SetQuietHours(t *testing.T) {
+	ctx := context.Background()
+
+	const expectedQuery = `UPDATE prj_user SET quietstart = (.+), quietend = (.+) WHERE userid = (.+);`
+
+	tests := []struct {
+		name    string
+		prepare func(mock sqlmock.Sqlmock)
+		wantErr bool
+	}{
+		{
+			name: "success SetQuietHours",
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(expectedQuery).WithArgs(1380, 480, 123).WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+			wantErr: false,
+		},
+		{
+			name: "error on SetQuietHours",
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(expectedQuery).WithArgs(1380, 480, 123).WillReturnError(errors.New("some error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			baseDB, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+			}
+			db := sqlx.NewDb(baseDB, "postgres")
+			defer db.Close()
+
+			tt.prepare(mock)
+
+			api := NewSQLAPI(db)
+			if err := api.SetQuietHours(ctx, 123, 1380, 480); (err != nil) != tt.wantErr {
+				t.Errorf("SetQuietHours() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("SetQuietHours() there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func Test_GetRefByID(t *testing.T) {
+	columns := []string{"refid", "linkid", "userid", "chapteralert", "include", "link"}
+
+	const expectedQuery = "SELECT ref_link_user.\\*, prj_link.link FROM ref_link_user JOIN prj_link ON prj_link.linkid = ref_link_user.linkid WHERE ref_link_user.refid = (.+);"
+
+	tests := []struct {
+		name    string
+		prepare func(mock sqlmock.Sqlmock)
+		wantErr bool
+	}{
+		{
+			name: "get ref",
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(expectedQuery).
+					WithArgs("refid").
+					WillReturnRows(sqlmock.NewRows(columns).FromCSVString("refid,linkid,1,true,глава,link"))
+			},
+			wantErr: false,
+		},
+		{
+			name: "get ref error",
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(expectedQuery).
+					WithArgs("refid").
+					WillReturnRows(sqlmock.NewRows(columns).FromCSVString("0,1"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			baseDB, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+			}
+			db := sqlx.NewDb(baseDB, "postgres")
+			defer db.Close()
+
+			tt.prepare(mock)
+
+			api := NewSQLAPI(db)
+
+			_, err = api.GetRefByID("refid")
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetRefByID() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("GetRefByID() there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func Test_GetUserRefs(t *testing.T) {
+	columns := []string{"refid", "linkid", "userid", "chapteralert", "include", "link", "kind", "title"}
+
+	const expectedQuery = "SELECT ref_link_user.\\*, prj_link.link, prj_link.kind, prj_link.title FROM ref_link_user JOIN prj_link ON prj_link.linkid = ref_link_user.linkid WHERE ref_link_user.userid = (.+) ORDER BY ref_link_user.created;"
+
+	tests := []struct {
+		name    string
+		prepare func(mock sqlmock.Sqlmock)
+		wantErr bool
+	}{
+		{
+			name: "get user refs",
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(expectedQuery).
+					WithArgs(123).
+					WillReturnRows(sqlmock.NewRows(columns).FromCSVString("refid,linkid,123,true,глава,link,book,title"))
+			},
+			wantErr: false,
+		},
+		{
+			name: "get user refs error",
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(expectedQuery).
+					WithArgs(123).
+					WillReturnError(errors.New("some error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			baseDB, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+			}
+			db := sqlx.NewDb(baseDB, "postgres")
+			defer db.Close()
+
+			tt.prepare(mock)
+
+			api := NewSQLAPI(db)
+
+			_, err = api.GetUserRefs(123)
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetUserRefs() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("GetUserRefs() there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func Test_UpdateRefFilters(t *testing.T) {
+	ctx := context.Background()
+	refR := RefRow{
+		RefID:           "refID",
+		ChapterAlert:    true,
+		PriceAlert:      false,
+		StatusAlert:     true,
+		BlogAlert:       true,
+		CommentAlert:    false,
+		AnnotationAlert: true,
+		Include:         "том",
+		Exclude:         "реклама",
+	}
+
+	const expectedQuery = `UPDATE ref_link_user SET chapteralert = (.+), pricealert = (.+), statusalert = (.+), blogalert = (.+), commentalert = (.+), annotationalert = (.+), include = (.+), exclude = (.+) WHERE refid = (.+);`
+
+	tests := []struct {
+		name    string
+		prepare func(mock sqlmock.Sqlmock)
+		wantErr bool
+	}{
+		{
+			name: "success update filters",
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(expectedQuery).WithArgs(refR.ChapterAlert, refR.PriceAlert, refR.StatusAlert, refR.BlogAlert, refR.CommentAlert, refR.AnnotationAlert, refR.Include, refR.Exclude, refR.RefID).WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+			wantErr: false,
+		},
+		{
+			name: "error on update filters",
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(expectedQuery).WithArgs(refR.ChapterAlert, refR.PriceAlert, refR.StatusAlert, refR.BlogAlert, refR.CommentAlert, refR.AnnotationAlert, refR.Include, refR.Exclude, refR.RefID).WillReturnError(errors.New("some error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			baseDB, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+			}
+			db := sqlx.NewDb(baseDB, "postgres")
+			defer db.Close()
+
+			tt.prepare(mock)
+
+			api := NewSQLAPI(db)
+			if err := api.UpdateRefFilters(ctx, refR); (err != nil) != tt.wantErr {
+				t.Errorf("UpdateRefFilters() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("UpdateRefFilters() there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func Test_SetPaused(t *testing.T) {
+	ctx := context.Background()
+
+	const expectedQuery = `UPDATE ref_link_user SET paused = (.+) WHERE userid = (.+) AND linkid = (.+);`
+
+	tests := []struct {
+		name    string
+		prepare func(mock sqlmock.Sqlmock)
+		wantErr bool
+	}{
+		{
+			name: "success SetPaused",
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(expectedQuery).WithArgs(true, 123, "linkid").WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+			wantErr: false,
+		},
+		{
+			name: "error on SetPaused",
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(expectedQuery).WithArgs(true, 123, "linkid").WillReturnError(errors.New("some error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			baseDB, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+			}
+			db := sqlx.NewDb(baseDB, "postgres")
+			defer db.Close()
+
+			tt.prepare(mock)
+
+			api := NewSQLAPI(db)
+			if err := api.SetPaused(ctx, 123, "linkid", true); (err != nil) != tt.wantErr {
+				t.Errorf("SetPaused() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("SetPaused() there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func Test_SetSnooze(t *testing.T) {
+	ctx := context.Background()
+	until := time.Date(2023, 5, 1, 9, 0, 0, 0, time.UTC)
+
+	const expectedQuery = `UPDATE ref_link_user SET snoozeuntil = (.+) WHERE userid = (.+) AND linkid = (.+);`
+
+	tests := []struct {
+		name    string
+		prepare func(mock sqlmock.Sqlmock)
+		wantErr bool
+	}{
+		{
+			name: "success SetSnooze",
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(expectedQuery).WithArgs(until, 123, "linkid").WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+			wantErr: false,
+		},
+		{
+			name: "error on SetSnooze",
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(expectedQuery).WithArgs(until, 123, "linkid").WillReturnError(errors.New("some error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			baseDB, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+			}
+			db := sqlx.NewDb(baseDB, "postgres")
+			defer db.Close()
+
+			tt.prepare(mock)
+
+			api := NewSQLAPI(db)
+			if err := api.SetSnooze(ctx, 123, "linkid", until); (err != nil) != tt.wantErr {
+				t.Errorf("SetSnooze() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("SetSnooze() there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func Test_SetUserSnooze(t *testing.T) {
+	ctx := context.Background()
+	until := time.Date(2023, 5, 1, 9, 0, 0, 0, time.UTC)
+
+	const expectedQuery = `UPDATE prj_user SET snoozeuntil = (.+) WHERE userid = (.+);`
+
+	tests := []struct {
+		name    string
+		prepare func(mock sqlmock.Sqlmock)
+		wantErr bool
+	}{
+		{
+			name: "success SetUserSnooze",
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(expectedQuery).WithArgs(until, 123).WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+			wantErr: false,
+		},
+		{
+			name: "error on SetUserSnooze",
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(expectedQuery).WithArgs(until, 123).WillReturnError(errors.New("some error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			baseDB, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+			}
+			db := sqlx.NewDb(baseDB, "postgres")
+			defer db.Close()
+
+			tt.prepare(mock)
+
+			api := NewSQLAPI(db)
+			if err := api.SetUserSnooze(ctx, 123, until); (err != nil) != tt.wantErr {
+				t.Errorf("SetUserSnooze() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("SetUserSnooze() there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func Test_RemoveOrphanLinks(t *testing.T) {
+	ctx := context.Background()
+
+	const expectedQuery = `DELETE FROM prj_link WHERE NOT EXISTS \(SELECT 1 FROM ref_link_user WHERE ref_link_user.linkid = prj_link.linkid\);`
+
+	tests := []struct {
+		name    string
+		prepare func(mock sqlmock.Sqlmock)
+		want    int64
+		wantErr bool
+	}{
+		{
+			name: "remove orphan links",
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(expectedQuery).WillReturnResult(sqlmock.NewResult(0, 2))
+			},
+			want:    2,
+			wantErr: false,
+		},
+		{
+			name: "remove orphan links err",
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(expectedQuery).WillReturnError(errors.New("some error"))
+			},
+			want:    0,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			baseDB, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+			}
+			db := sqlx.NewDb(baseDB, "postgres")
+			defer db.Close()
+
+			tt.prepare(mock)
+
+			api := NewSQLAPI(db)
+
+			got, err := api.RemoveOrphanLinks(ctx)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("RemoveOrphanLinks() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if got != tt.want {
+				t.Errorf("RemoveOrphanLinks() = %v, want %v", got, tt.want)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("RemoveOrphanLinks() there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func Test_SetChatID(t *testing.T) {
+	ctx := context.Background()
+
+	const expectedQuery = `UPDATE prj_user SET chatid = (.+) WHERE userid = (.+);`
+
+	tests := []struct {
+		name    string
+		prepare func(mock sqlmock.Sqlmock)
+		wantErr bool
+	}{
+		{
+			name: "success SetChatID",
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(expectedQuery).WithArgs(int64(-100123), 123).WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+			wantErr: false,
+		},
+		{
+			name: "error on SetChatID",
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(expectedQuery).WithArgs(int64(-100123), 123).WillReturnError(errors.New("some error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			baseDB, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+			}
+			db := sqlx.NewDb(baseDB, "postgres")
+			defer db.Close()
+
+			tt.prepare(mock)
+
+			api := NewSQLAPI(db)
+			if err := api.SetChatID(ctx, 123, -100123); (err != nil) != tt.wantErr {
+				t.Errorf("SetChatID() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("SetChatID() there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func Test_MigrateUser(t *testing.T) {
+	ctx := context.Background()
+
+	const expectedQuery = `UPDATE prj_user SET userid = (.+), chatid = CASE WHEN chatid = (.+) THEN (.+) ELSE chatid END WHERE userid = (.+);`
+
+	tests := []struct {
+		name    string
+		prepare func(mock sqlmock.Sqlmock)
+		wantErr bool
+	}{
+		{
+			name: "success MigrateUser",
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(expectedQuery).WithArgs(int64(-100123), int64(-123)).WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+			wantErr: false,
+		},
+		{
+			name: "error on MigrateUser",
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(expectedQuery).WithArgs(int64(-100123), int64(-123)).WillReturnError(errors.New("some error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			baseDB, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+			}
+			db := sqlx.NewDb(baseDB, "postgres")
+			defer db.Close()
+
+			tt.prepare(mock)
+
+			api := NewSQLAPI(db)
+			if err := api.MigrateUser(ctx, -123, -100123); (err != nil) != tt.wantErr {
+				t.Errorf("MigrateUser() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("MigrateUser() there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func Test_SetBanned(t *testing.T) {
+	ctx := context.Background()
+
+	const expectedQuery = `UPDATE prj_user SET banned = (.+) WHERE userid = (.+);`
+
+	tests := []struct {
+		name    string
+		prepare func(mock sqlmock.Sqlmock)
+		wantErr bool
+	}{
+		{
+			name: "success SetBanned",
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(expectedQuery).WithArgs(true, 123).WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+			wantErr: false,
+		},
+		{
+			name: "error on SetBanned",
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(expectedQuery).WithArgs(true, 123).WillReturnError(errors.New("some error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			baseDB, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+			}
+			db := sqlx.NewDb(baseDB, "postgres")
+			defer db.Close()
+
+			tt.prepare(mock)
+
+			api := NewSQLAPI(db)
+			if err := api.SetBanned(ctx, 123, true); (err != nil) != tt.wantErr {
+				t.Errorf("SetBanned() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("SetBanned() there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func Test_SetBlocked(t *testing.T) {
+	ctx := context.Background()
+
+	const expectedQuery = `UPDATE prj_user SET blocked = (.+) WHERE userid = (.+);`
+
+	tests := []struct {
+		name    string
+		prepare func(mock sqlmock.Sqlmock)
+		wantErr bool
+	}{
+		{
+			name: "success SetBlocked",
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(expectedQuery).WithArgs(true, 123).WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+			wantErr: false,
+		},
+		{
+			name: "error on SetBlocked",
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(expectedQuery).WithArgs(true, 123).WillReturnError(errors.New("some error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			baseDB, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+			}
+			db := sqlx.NewDb(baseDB, "postgres")
+			defer db.Close()
+
+			tt.prepare(mock)
+
+			api := NewSQLAPI(db)
+			if err := api.SetBlocked(ctx, 123, true); (err != nil) != tt.wantErr {
+				t.Errorf("SetBlocked() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("SetBlocked() there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func Test_ResetChatID(t *testing.T) {
+	ctx := context.Background()
+
+	const expectedQuery = `UPDATE prj_user SET chatid = userid WHERE chatid = (.+) AND userid <> (.+);`
+
+	tests := []struct {
+		name    string
+		prepare func(mock sqlmock.Sqlmock)
+		want    int64
+		wantErr bool
+	}{
+		{
+			name: "success ResetChatID",
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(expectedQuery).WithArgs(int64(-100123)).WillReturnResult(sqlmock.NewResult(0, 2))
+			},
+			want:    2,
+			wantErr: false,
+		},
+		{
+			name: "error on ResetChatID",
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(expectedQuery).WithArgs(int64(-100123)).WillReturnError(errors.New("some error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			baseDB, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+			}
+			db := sqlx.NewDb(baseDB, "postgres")
+			defer db.Close()
+
+			tt.prepare(mock)
+
+			api := NewSQLAPI(db)
+			got, err := api.ResetChatID(ctx, -100123)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ResetChatID() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if got != tt.want {
+				t.Errorf("ResetChatID() = %d, want %d", got, tt.want)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("ResetChatID() there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func Test_GetActiveUsers(t *testing.T) {
+	columns := []string{"userid", "nameuser", "chatid", "banned"}
+
+	const expectedQuery = "SELECT \\* FROM prj_user WHERE NOT banned AND NOT blocked ORDER BY userid;"
+
+	tests := []struct {
+		name    string
+		prepare func(mock sqlmock.Sqlmock)
+		wantErr bool
+	}{
+		{
+			name: "success GetActiveUsers",
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(expectedQuery).
+					WillReturnRows(sqlmock.NewRows(columns).FromCSVString("1,name,1,false"))
+			},
+			wantErr: false,
+		},
+		{
+			name: "error on GetActiveUsers",
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(expectedQuery).
+					WillReturnRows(sqlmock.NewRows(columns).FromCSVString("0,1"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			baseDB, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+			}
+			db := sqlx.NewDb(baseDB, "postgres")
+			defer db.Close()
+
+			tt.prepare(mock)
+
+			api := NewSQLAPI(db)
+
+			_, err = api.GetActiveUsers()
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetActiveUsers() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("GetActiveUsers() there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func Test_GetStats(t *testing.T) {
+	columns := []string{"users", "banned", "blocked", "links", "subscriptions"}
+
+	const expectedQuery = "SELECT (.+) AS users, (.+) AS banned, (.+) AS blocked, (.+) AS links, (.+) AS subscriptions;"
+
+	tests := []struct {
+		name    string
+		prepare func(mock sqlmock.Sqlmock)
+		want    *StatsRow
+		wantErr bool
+	}{
+		{
+			name: "success GetStats",
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(expectedQuery).
+					WillReturnRows(sqlmock.NewRows(columns).FromCSVString("10,1,2,25,40"))
+			},
+			want:    &StatsRow{Users: 10, Banned: 1, Blocked: 2, Links: 25, Subscriptions: 40},
+			wantErr: false,
+		},
+		{
+			name: "error on GetStats",
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(expectedQuery).WillReturnError(errors.New("some error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			baseDB, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+			}
+			db := sqlx.NewDb(baseDB, "postgres")
+			defer db.Close()
+
+			tt.prepare(mock)
+
+			api := NewSQLAPI(db)
+
+			got, err := api.GetStats()
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetStats() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if tt.want != nil && *got != *tt.want {
+				t.Errorf("GetStats() = %+v, want %+v", got, tt.want)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("GetStats() there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func Test_GetLastUpdateID(t *testing.T) {
+	ctx := context.Background()
+	after := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	const expectedQuery = `SELECT COALESCE\(\(SELECT updateid FROM processed_update WHERE processed >= (.+) ORDER BY processed DESC, updateid DESC LIMIT 1\), 0\);`
+
+	tests := []struct {
+		name    string
+		prepare func(mock sqlmock.Sqlmock)
+		want    int
+		wantErr bool
+	}{
+		{
+			name: "last update",
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(expectedQuery).WithArgs(after).WillReturnRows(sqlmock.NewRows([]string{"coalesce"}).AddRow(42))
+			},
+			want:    42,
+			wantErr: false,
+		},
+		{
+			name: "error on GetLastUpdateID",
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(expectedQuery).WithArgs(after).WillReturnError(errors.New("some error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			baseDB, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+			}
+			db := sqlx.NewDb(baseDB, "postgres")
+			defer db.Close()
+
+			tt.prepare(mock)
+
+			api := NewSQLAPI(db)
+			got, err := api.GetLastUpdateID(ctx, after)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetLastUpdateID() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if got != tt.want {
+				t.Errorf("GetLastUpdateID() = %d, want %d", got, tt.want)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("GetLastUpdateID() there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func Test_ClaimUpdate(t *testing.T) {
+	ctx := context.Background()
+
+	const expectedQuery = `INSERT INTO processed_update \(updateid, processed\) VALUES \((.+), now\(\)\) ON CONFLICT DO NOTHING;`
+
+	tests := []struct {
+		name    string
+		prepare func(mock sqlmock.Sqlmock)
+		want    bool
+		wantErr bool
+	}{
+		{
+			name: "new update",
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(expectedQuery).WithArgs(42).WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			want:    true,
+			wantErr: false,
+		},
+		{
+			name: "duplicate update",
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(expectedQuery).WithArgs(42).WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			want:    false,
+			wantErr: false,
+		},
+		{
+			name: "error on ClaimUpdate",
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(expectedQuery).WithArgs(42).WillReturnError(errors.New("some error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			baseDB, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+			}
+			db := sqlx.NewDb(baseDB, "postgres")
+			defer db.Close()
+
+			tt.prepare(mock)
+
+			api := NewSQLAPI(db)
+			got, err := api.ClaimUpdate(ctx, 42)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ClaimUpdate() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if got != tt.want {
+				t.Errorf("ClaimUpdate() = %v, want %v", got, tt.want)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("ClaimUpdate() there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func Test_PruneUpdates(t *testing.T) {
+	ctx := context.Background()
+	before := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	const expectedQuery = `DELETE FROM processed_update WHERE processed < (.+);`
+
+	tests := []struct {
+		name    string
+		prepare func(mock sqlmock.Sqlmock)
+		wantErr bool
+	}{
+		{
+			name: "success PruneUpdates",
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(expectedQuery).WithArgs(before).WillReturnResult(sqlmock.NewResult(0, 10))
+			},
+			wantErr: false,
+		},
+		{
+			name: "error on PruneUpdates",
+			prepare: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(expectedQuery).WithArgs(before).WillReturnError(errors.New("some error"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			baseDB, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+			}
+			db := sqlx.NewDb(baseDB, "postgres")
+			defer db.Close()
+
+			tt.prepare(mock)
+
+			api := NewSQLAPI(db)
+			if err := api.PruneUpdates(ctx, before); (err != nil) != tt.wantErr {
+				t.Errorf("PruneUpdates() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Errorf("PruneUpdates() there were unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
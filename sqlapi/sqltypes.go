@@ -1,21 +1,128 @@
 package sqlapi
 
+import "time"
+
 // UserRow ...
 type UserRow struct {
-	UserID   int    `db:"userid"`
-	NameUser string `db:"nameuser"`
-	ChatID   int64  `db:"chatid"`
+	UserID      int       `db:"userid"`
+	NameUser    string    `db:"nameuser"`
+	ChatID      int64     `db:"chatid"`
+	Delivery    string    `db:"delivery"`
+	DigestHour  int       `db:"digesthour"`
+	LastDigest  time.Time `db:"lastdigest"`
+	Timezone    string    `db:"timezone"`
+	QuietStart  int       `db:"quietstart"`
+	QuietEnd    int       `db:"quietend"`
+	SnoozeUntil time.Time `db:"snoozeuntil"`
+	Lang        string    `db:"lang"`
+	Banned      bool      `db:"banned"`
+	// Blocked — пользователь заблокировал бота или удалил его из группы.
+	Blocked bool `db:"blocked"`
 }
 
-// LinkRow ...
+// LinkRow ... Paused и SnoozeUntil берутся из подписки и заполняются
+// только списком ссылок пользователя.
 type LinkRow struct {
-	LinkID string `db:"linkid"`
-	Link   string `db:"link"`
+	LinkID      string    `db:"linkid"`
+	Link        string    `db:"link"`
+	Title       string    `db:"title"`
+	Chapters    int       `db:"chapters"`
+	Checked     bool      `db:"checked"`
+	Status      string    `db:"status"`
+	Kind        string    `db:"kind"`
+	Volumes     int       `db:"volumes"`
+	LastCheck   time.Time `db:"lastcheck"`
+	Annotation  string    `db:"annotation"`
+	Paused      bool      `db:"paused"`
+	SnoozeUntil time.Time `db:"snoozeuntil"`
 }
 
-// RefRow ...
+// RefRow ... Include и Exclude — ключевые слова через запятую для записей
+// блога и комментариев. Link, Kind и Title заполняются только запросами,
+// которые присоединяют prj_link.
 type RefRow struct {
-	RefID  string `db:"refid"`
-	LinkID string `db:"linkid"`
-	UserID int    `db:"userid"`
+	RefID           string    `db:"refid"`
+	LinkID          string    `db:"linkid"`
+	UserID          int       `db:"userid"`
+	PriceAlert      bool      `db:"pricealert"`
+	PriceBelow      int       `db:"pricebelow"`
+	ChapterAlert    bool      `db:"chapteralert"`
+	StatusAlert     bool      `db:"statusalert"`
+	BlogAlert       bool      `db:"blogalert"`
+	CommentAlert    bool      `db:"commentalert"`
+	AnnotationAlert bool      `db:"annotationalert"`
+	Include         string    `db:"include"`
+	Exclude         string    `db:"exclude"`
+	Paused          bool      `db:"paused"`
+	SnoozeUntil     time.Time `db:"snoozeuntil"`
+	Created         time.Time `db:"created"`
+	Link            string    `db:"link"`
+	Kind            string    `db:"kind"`
+	Title           string    `db:"title"`
+}
+
+// PriceRow ...
+type PriceRow struct {
+	PriceID  string    `db:"priceid"`
+	LinkID   string    `db:"linkid"`
+	Price    int       `db:"price"`
+	Discount int       `db:"discount"`
+	Free     bool      `db:"free"`
+	Created  time.Time `db:"created"`
+}
+
+// SubscriberRow ...
+type SubscriberRow struct {
+	UserID          int       `db:"userid"`
+	ChatID          int64     `db:"chatid"`
+	PriceAlert      bool      `db:"pricealert"`
+	PriceBelow      int       `db:"pricebelow"`
+	ChapterAlert    bool      `db:"chapteralert"`
+	StatusAlert     bool      `db:"statusalert"`
+	Delivery        string    `db:"delivery"`
+	BlogAlert       bool      `db:"blogalert"`
+	CommentAlert    bool      `db:"commentalert"`
+	AnnotationAlert bool      `db:"annotationalert"`
+	Include         string    `db:"include"`
+	Exclude         string    `db:"exclude"`
+	Timezone        string    `db:"timezone"`
+	QuietStart      int       `db:"quietstart"`
+	QuietEnd        int       `db:"quietend"`
+	Paused          bool      `db:"paused"`
+	SnoozeUntil     time.Time `db:"snoozeuntil"`
+	UserSnoozeUntil time.Time `db:"usersnoozeuntil"`
+	Lang            string    `db:"lang"`
+}
+
+// ItemRow ...
+type ItemRow struct {
+	ItemID     string `db:"itemid"`
+	LinkID     string `db:"linkid"`
+	Kind       string `db:"kind"`
+	ExternalID string `db:"externalid"`
+	Title      string `db:"title"`
+	Position   int    `db:"position"`
+	Status     string `db:"status"`
+	Comments   int    `db:"comments"`
+}
+
+// NoticeRow ...
+type NoticeRow struct {
+	NoticeID string    `db:"noticeid"`
+	UserID   int       `db:"userid"`
+	ChatID   int64     `db:"chatid"`
+	LinkID   string    `db:"linkid"`
+	Text     string    `db:"text"`
+	Created  time.Time `db:"created"`
+	Link     string    `db:"link"`
+	Title    string    `db:"title"`
+}
+
+// StatsRow — общее число пользователей, ссылок и подписок.
+type StatsRow struct {
+	Users         int `db:"users"`
+	Banned        int `db:"banned"`
+	Blocked       int `db:"blocked"`
+	Links         int `db:"links"`
+	Subscriptions int `db:"subscriptions"`
 }
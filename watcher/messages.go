@@ -0,0 +1,71 @@
+package watcher
+
+import (
+	"github.com/EfimoffN/authorBot/lib/tz"
+	"github.com/EfimoffN/authorBot/render"
+	"github.com/EfimoffN/authorBot/sources"
+)
+
+// Смены статуса книги, для которых в шаблоне book_status свой текст.
+const (
+	statusCompleted  = "completed"
+	statusFrozen     = "frozen"
+	statusHidden     = "hidden"
+	statusUnfrozen   = "unfrozen"
+	statusVisible    = "visible"
+	statusInProgress = "in_progress"
+)
+
+// changeData — данные шаблона оповещения об изменении. Шаблон выбирается
+// по типу изменения.
+type changeData struct {
+	sources.Change
+	Link string
+	// Time — время изменения в часовом поясе подписчика, пустое, если
+	// площадка его не сообщила.
+	Time string
+	// Book — книга, у которой сменился статус: сама ссылка или том цикла.
+	Book bookStatus
+}
+
+type bookStatus struct {
+	Title  string
+	Status string
+}
+
+// changeMessage формирует текст оповещения об изменении в разметке HTML на
+// языке подписчика lang. Время выводится в часовом поясе подписчика
+// timezone.
+func changeMessage(r *render.Renderer, c sources.Change, link, timezone, lang string) (string, error) {
+	data := changeData{Change: c, Link: link}
+
+	if !c.Time.IsZero() {
+		data.Time = tz.Format(c.Time, timezone)
+	}
+
+	switch c.Type {
+	case sources.ChangeStatus:
+		data.Book = bookStatus{Title: c.Title, Status: statusChange(c.OldStatus, c.NewStatus)}
+	case sources.ChangeVolumeStatus:
+		data.Book = bookStatus{Title: c.Item.Title, Status: statusChange(c.OldStatus, c.NewStatus)}
+	}
+
+	return r.Execute(lang, c.Type, data)
+}
+
+func statusChange(oldStatus, newStatus string) string {
+	switch {
+	case newStatus == sources.StatusCompleted:
+		return statusCompleted
+	case newStatus == sources.StatusFrozen:
+		return statusFrozen
+	case newStatus == sources.StatusHidden:
+		return statusHidden
+	case oldStatus == sources.StatusFrozen:
+		return statusUnfrozen
+	case oldStatus == sources.StatusHidden:
+		return statusVisible
+	default:
+		return statusInProgress
+	}
+}
@@ -0,0 +1,125 @@
+package watcher
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/EfimoffN/authorBot/render"
+	"github.com/EfimoffN/authorBot/sources"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+// goldenSets — каталоги эталонных текстов для каждого языка шаблонов.
+var goldenSets = []struct {
+	lang string
+	dir  string
+}{
+	{lang: render.DefaultLang, dir: "testdata"},
+	{lang: "en", dir: filepath.Join("testdata", "en")},
+}
+
+func Test_changeMessage(t *testing.T) {
+	r, err := render.New("")
+	if err != nil {
+		t.Fatalf("render.New() error = %v", err)
+	}
+
+	link := "https://author.today/work/1?a=1&b=2"
+	title := `Книга <b>"Тени" & свет</b>`
+	post := sources.Item{Title: "Итоги <года> & планы", URL: "https://author.today/post/7"}
+	volume := sources.Item{Title: "Том <второй>", URL: "https://author.today/work/2", Position: 2, SeriesTitle: "Цикл & co"}
+
+	tests := []struct {
+		golden string
+		change sources.Change
+	}{
+		{
+			golden: "chapters",
+			change: sources.Change{Type: sources.ChangeChapters, Title: title, Count: 2},
+		},
+		{
+			golden: "chapters_time",
+			change: sources.Change{Type: sources.ChangeChapters, Title: title, Count: 1, Time: time.Date(2023, 5, 10, 9, 30, 0, 0, time.UTC)},
+		},
+		{
+			golden: "price",
+			change: sources.Change{Type: sources.ChangePrice, Title: title, OldPrice: 150, NewPrice: 100},
+		},
+		{
+			golden: "price_discount",
+			change: sources.Change{Type: sources.ChangePrice, Title: title, OldPrice: 150, NewFree: true, Discount: 100},
+		},
+		{
+			golden: "status_completed",
+			change: sources.Change{Type: sources.ChangeStatus, Title: title, OldStatus: "in_progress", NewStatus: sources.StatusCompleted},
+		},
+		{
+			golden: "status_unfrozen",
+			change: sources.Change{Type: sources.ChangeStatus, Title: title, OldStatus: sources.StatusFrozen, NewStatus: "in_progress"},
+		},
+		{
+			golden: "new_work",
+			change: sources.Change{Type: sources.ChangeNewWork, Title: "Автор <Ник>", Item: volume},
+		},
+		{
+			golden: "new_series",
+			change: sources.Change{Type: sources.ChangeNewSeries, Title: "Автор <Ник>", Item: volume},
+		},
+		{
+			golden: "series_work",
+			change: sources.Change{Type: sources.ChangeSeriesWork, Title: "Автор <Ник>", Item: volume},
+		},
+		{
+			golden: "new_volume",
+			change: sources.Change{Type: sources.ChangeNewVolume, Title: "Цикл & co", Item: volume},
+		},
+		{
+			golden: "volume_status",
+			change: sources.Change{Type: sources.ChangeVolumeStatus, Title: "Цикл & co", OldStatus: sources.StatusHidden, NewStatus: "in_progress", Item: volume},
+		},
+		{
+			golden: "new_post",
+			change: sources.Change{Type: sources.ChangeNewPost, Title: "Автор <Ник>", Item: post},
+		},
+		{
+			golden: "comments",
+			change: sources.Change{Type: sources.ChangeComments, Title: "Автор <Ник>", Count: 3, Item: post},
+		},
+		{
+			golden: "annotation",
+			change: sources.Change{Type: sources.ChangeAnnotation, Title: title},
+		},
+	}
+
+	for _, set := range goldenSets {
+		for _, tt := range tests {
+			t.Run(set.lang+"/"+tt.golden, func(t *testing.T) {
+				got, err := changeMessage(r, tt.change, link, "Europe/Moscow", set.lang)
+				if err != nil {
+					t.Fatalf("changeMessage() error = %v", err)
+				}
+
+				golden := filepath.Join(set.dir, tt.golden+".golden")
+
+				if *update {
+					if err := os.WriteFile(golden, []byte(got), 0o644); err != nil {
+						t.Fatalf("write golden file: %v", err)
+					}
+				}
+
+				want, err := os.ReadFile(golden)
+				if err != nil {
+					t.Fatalf("read golden file: %v", err)
+				}
+
+				if got != string(want) {
+					t.Errorf("changeMessage() = %q, want %q", got, want)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package watcher
+
+import (
+	"github.com/EfimoffN/authorBot/lib/e"
+	"github.com/EfimoffN/authorBot/sources"
+	"github.com/EfimoffN/authorBot/sqlapi"
+)
+
+// storedState — состояние ссылки, восстановленное из базы, вместе со
+// строками, из которых оно собрано.
+type storedState struct {
+	state *sources.State
+	price *sqlapi.PriceRow
+	items map[string]*sqlapi.ItemRow
+}
+
+func (w *Watcher) loadState(link *sqlapi.LinkRow) (*storedState, error) {
+	priceRow, err := w.Event.GetLastPrice(link.LinkID)
+	if err != nil {
+		return nil, e.Wrap("get last price failed with an error: ", err)
+	}
+
+	itemRows, err := w.Event.GetLinkItems(link.LinkID)
+	if err != nil {
+		return nil, e.Wrap("get link items failed with an error: ", err)
+	}
+
+	stored := &storedState{
+		state: &sources.State{
+			Kind:       link.Kind,
+			Title:      link.Title,
+			Chapters:   link.Chapters,
+			Status:     link.Status,
+			Annotation: link.Annotation,
+		},
+		price: priceRow,
+		items: make(map[string]*sqlapi.ItemRow, len(itemRows)),
+	}
+
+	if priceRow != nil {
+		stored.state.Price = priceRow.Price
+		stored.state.Discount = priceRow.Discount
+		stored.state.Free = priceRow.Free
+		stored.state.HasPrice = true
+	}
+
+	for _, i := range itemRows {
+		stored.items[i.Kind+i.ExternalID] = i
+		stored.state.Items = append(stored.state.Items, sources.Item{
+			Kind:     i.Kind,
+			ID:       i.ExternalID,
+			Title:    i.Title,
+			Position: i.Position,
+			Status:   i.Status,
+			Comments: i.Comments,
+		})
+	}
+
+	return stored, nil
+}
+
+func (w *Watcher) saveState(link *sqlapi.LinkRow, stored *storedState, state *sources.State) error {
+	p := stored.price
+	if state.HasPrice && (p == nil || p.Price != state.Price || p.Discount != state.Discount || p.Free != state.Free) {
+		if err := w.Event.AddPrice(link.LinkID, state.Price, state.Discount, state.Free); err != nil {
+			return e.Wrap("add price failed with an error: ", err)
+		}
+	}
+
+	volumes := 0
+
+	for _, i := range state.Items {
+		if i.Kind == sources.ItemVolume {
+			volumes++
+		}
+
+		row, ok := stored.items[i.Kind+i.ID]
+		if !ok {
+			newRow := sqlapi.ItemRow{LinkID: link.LinkID, Kind: i.Kind, ExternalID: i.ID, Title: i.Title, Position: i.Position, Status: i.Status, Comments: i.Comments}
+			if err := w.Event.AddLinkItem(newRow); err != nil {
+				return e.Wrap("add link item failed with an error: ", err)
+			}
+
+			continue
+		}
+
+		if row.Title == i.Title && row.Position == i.Position && row.Status == i.Status && row.Comments == i.Comments {
+			continue
+		}
+
+		row.Title = i.Title
+		row.Position = i.Position
+		row.Status = i.Status
+		row.Comments = i.Comments
+
+		if err := w.Event.UpdateLinkItem(*row); err != nil {
+			return e.Wrap("update link item failed with an error: ", err)
+		}
+	}
+
+	link.Title = state.Title
+	link.Chapters = state.Chapters
+	link.Status = state.Status
+	link.Annotation = state.Annotation
+	link.Volumes = volumes
+	link.Checked = true
+
+	if err := w.Event.UpdateLink(*link); err != nil {
+		return e.Wrap("update link failed with an error: ", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,61 @@
+package watcher
+
+import (
+	"sync"
+	"time"
+)
+
+// statsWindow — период, за который считаются проверки ссылок.
+const statsWindow = time.Hour
+
+// CheckStats — число проверок ссылок и неудачных проверок за последний час.
+type CheckStats struct {
+	Checks   int
+	Failures int
+}
+
+type checkResult struct {
+	at     time.Time
+	failed bool
+}
+
+// checkLog хранит результаты проверок за последний час.
+type checkLog struct {
+	mu      sync.Mutex
+	results []checkResult
+}
+
+func (l *checkLog) add(at time.Time, failed bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.prune(at)
+	l.results = append(l.results, checkResult{at: at, failed: failed})
+}
+
+func (l *checkLog) stats(now time.Time) CheckStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.prune(now)
+
+	stats := CheckStats{Checks: len(l.results)}
+	for _, r := range l.results {
+		if r.failed {
+			stats.Failures++
+		}
+	}
+
+	return stats
+}
+
+// prune отбрасывает результаты старше statsWindow. Результаты хранятся в
+// порядке проверок.
+func (l *checkLog) prune(now time.Time) {
+	i := 0
+	for i < len(l.results) && now.Sub(l.results[i].at) > statsWindow {
+		i++
+	}
+
+	l.results = l.results[i:]
+}
@@ -0,0 +1,317 @@
+package watcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/EfimoffN/authorBot/events"
+	"github.com/EfimoffN/authorBot/lib/e"
+	"github.com/EfimoffN/authorBot/lib/keywords"
+	"github.com/EfimoffN/authorBot/lib/logger"
+	"github.com/EfimoffN/authorBot/metrics"
+	"github.com/EfimoffN/authorBot/notifier"
+	"github.com/EfimoffN/authorBot/render"
+	"github.com/EfimoffN/authorBot/sources"
+	"github.com/EfimoffN/authorBot/sqlapi"
+)
+
+const defaultInterval = 30 * time.Minute
+
+// heartbeatMargin — сколько сверх интервала проверок может пройти без
+// признаков жизни, прежде чем цикл проверок считается зависшим. Покрывает
+// проверку одной медленной ссылки.
+const heartbeatMargin = 5 * time.Minute
+
+type Watcher struct {
+	Notifier *notifier.Notifier
+	Event    events.IEvent
+	Sources  *sources.Registry
+	Renderer *render.Renderer
+	Log      *slog.Logger
+	CTX      context.Context
+	// interval — интервал проверок в наносекундах, меняется SetInterval.
+	interval atomic.Int64
+	// reset сообщает циклу проверок, что интервал изменился.
+	reset chan struct{}
+	// mu не дает проверять ссылки одновременно по расписанию и по команде.
+	mu     sync.Mutex
+	checks checkLog
+	// heartbeat — время последней проверки ссылки или начала цикла
+	// проверок.
+	heartbeat atomic.Value
+}
+
+func NewWatcher(notifier *notifier.Notifier, event events.IEvent, sources *sources.Registry, renderer *render.Renderer, interval time.Duration, log *slog.Logger, ctx context.Context) *Watcher {
+	if interval <= 0 {
+		interval = defaultInterval
+	}
+
+	w := &Watcher{
+		Notifier: notifier,
+		Event:    event,
+		Sources:  sources,
+		Renderer: renderer,
+		Log:      log,
+		CTX:      ctx,
+		reset:    make(chan struct{}, 1),
+	}
+	w.interval.Store(int64(interval))
+	w.beat()
+
+	return w
+}
+
+// Alive возвращает ошибку, если цикл проверок давно не подавал признаков
+// жизни.
+func (w *Watcher) Alive(ctx context.Context) error {
+	last, _ := w.heartbeat.Load().(time.Time)
+
+	if stale := time.Since(last); stale > w.Interval()+heartbeatMargin {
+		return fmt.Errorf("no checks for %s", stale.Round(time.Second))
+	}
+
+	return nil
+}
+
+// Interval возвращает текущий интервал проверок.
+func (w *Watcher) Interval() time.Duration {
+	return time.Duration(w.interval.Load())
+}
+
+// SetInterval меняет интервал проверок. Следующая проверка будет через
+// новый интервал, идущая проверка не прерывается.
+func (w *Watcher) SetInterval(interval time.Duration) {
+	if interval <= 0 {
+		interval = defaultInterval
+	}
+
+	if time.Duration(w.interval.Swap(int64(interval))) == interval {
+		return
+	}
+
+	select {
+	case w.reset <- struct{}{}:
+	default:
+	}
+}
+
+func (w *Watcher) beat() {
+	w.heartbeat.Store(time.Now())
+}
+
+// Start проверяет все отслеживаемые ссылки с заданным интервалом,
+// пока не будет отменен контекст.
+func (w *Watcher) Start() error {
+	ticker := time.NewTicker(w.Interval())
+	defer ticker.Stop()
+
+	w.checkAll()
+
+	for {
+		select {
+		case <-w.CTX.Done():
+			return nil
+		case <-w.reset:
+			ticker.Reset(w.Interval())
+		case <-ticker.C:
+			w.checkAll()
+		}
+	}
+}
+
+func (w *Watcher) checkAll() {
+	w.beat()
+
+	linkRows, err := w.Event.GetAllLinks()
+	if err != nil {
+		w.Log.Error("get links", logger.Err(err))
+		return
+	}
+
+	for i, l := range linkRows {
+		metrics.QueueDepth.WithLabelValues(metrics.QueueWatcher).Set(float64(len(linkRows) - i))
+
+		log := w.Log.With(logger.KeyLinkID, l.LinkID, logger.KeyLink, l.Link)
+
+		if err := w.check(log, l); err != nil {
+			log.Error("check link", logger.Err(err))
+		}
+	}
+
+	metrics.QueueDepth.WithLabelValues(metrics.QueueWatcher).Set(0)
+}
+
+// CheckNow сразу проверяет отслеживаемую ссылку, не дожидаясь очередной
+// проверки по расписанию.
+func (w *Watcher) CheckNow(link string) error {
+	link, _, err := w.Sources.Canonicalize(link)
+	if err != nil {
+		return e.Wrap("canonicalize link failed with an error: ", err)
+	}
+
+	linkRow, err := w.Event.GetLinkByLink(link)
+	if err != nil {
+		return e.Wrap("get link by link failed with an error: ", err)
+	}
+
+	if linkRow == nil {
+		return e.Wrap("Check now: ", events.ErrLinkNotDB)
+	}
+
+	return w.check(w.Log.With(logger.KeyLinkID, linkRow.LinkID, logger.KeyLink, linkRow.Link), linkRow)
+}
+
+// Stats возвращает число проверок ссылок и ошибок за последний час.
+func (w *Watcher) Stats() CheckStats {
+	return w.checks.stats(time.Now())
+}
+
+func (w *Watcher) check(log *slog.Logger, link *sqlapi.LinkRow) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	err := w.checkLink(log, link)
+	w.checks.add(time.Now(), err != nil)
+	w.beat()
+
+	return err
+}
+
+// checkLink загружает новое состояние ссылки, сравнивает его с
+// сохраненным, рассылает оповещения и сохраняет новое состояние.
+func (w *Watcher) checkLink(log *slog.Logger, link *sqlapi.LinkRow) error {
+	source, err := w.Sources.Lookup(link.Link)
+	if err != nil {
+		return e.Wrap("lookup source failed with an error: ", err)
+	}
+
+	stored, err := w.loadState(link)
+	if err != nil {
+		return e.Wrap("load state failed with an error: ", err)
+	}
+
+	state, err := source.Fetch(w.CTX, link.Link, link.Kind)
+	if errors.Is(err, sources.ErrHidden) && link.Checked {
+		hidden := *stored.state
+		hidden.Status = sources.StatusHidden
+		state = &hidden
+		err = nil
+	}
+
+	if err != nil {
+		return e.Wrap("fetch state failed with an error: ", err)
+	}
+
+	var prev *sources.State
+	if link.Checked {
+		prev = stored.state
+	}
+
+	changes := source.Diff(prev, state)
+	log.Debug("link checked", "changes", len(changes))
+
+	if len(changes) > 0 {
+		subRows, err := w.Event.GetSubscribers(link.LinkID)
+		if err != nil {
+			return e.Wrap("get subscribers failed with an error: ", err)
+		}
+
+		w.loadComments(log, source, changes)
+
+		for _, c := range changes {
+			metrics.Changes.WithLabelValues(c.Type).Inc()
+			w.notifyChange(log, subRows, c, link)
+		}
+	}
+
+	if err := w.saveState(link, stored, state); err != nil {
+		return e.Wrap("save state failed with an error: ", err)
+	}
+
+	return nil
+}
+
+// loadComments дописывает в изменения ChangeComments тексты новых
+// комментариев. Если площадка их не отдает, изменение остается без текста
+// и доходит только до подписчиков без слов для включения.
+func (w *Watcher) loadComments(log *slog.Logger, source sources.Source, changes []sources.Change) {
+	commentSource, ok := source.(sources.CommentSource)
+	if !ok {
+		return
+	}
+
+	for i := range changes {
+		if changes[i].Type != sources.ChangeComments {
+			continue
+		}
+
+		comments, err := commentSource.Comments(w.CTX, changes[i].Item, changes[i].Count)
+		if err != nil {
+			log.Warn("load comments", logger.Err(err))
+			continue
+		}
+
+		changes[i].Comments = comments
+	}
+}
+
+func (w *Watcher) notifyChange(log *slog.Logger, subRows []*sqlapi.SubscriberRow, change sources.Change, link *sqlapi.LinkRow) {
+	now := time.Now()
+
+	for _, s := range subRows {
+		if isPaused(s, now) || !wantsChange(s, change) {
+			continue
+		}
+
+		msg, err := changeMessage(w.Renderer, change, link.Link, s.Timezone, s.Lang)
+		if err != nil {
+			log.Error("change message", "change", change.Type, logger.KeyUserID, s.UserID, logger.Err(err))
+
+			continue
+		}
+
+		w.Notifier.Notify(s, link.LinkID, msg)
+	}
+}
+
+// isPaused сообщает, поставил ли подписчик ссылку или все оповещения на паузу.
+func isPaused(s *sqlapi.SubscriberRow, now time.Time) bool {
+	return s.Paused || s.SnoozeUntil.After(now) || s.UserSnoozeUntil.After(now)
+}
+
+// wantsChange сообщает, подписан ли пользователь на такое изменение.
+func wantsChange(s *sqlapi.SubscriberRow, change sources.Change) bool {
+	switch change.Type {
+	case sources.ChangeChapters:
+		return s.ChapterAlert
+	case sources.ChangeStatus, sources.ChangeVolumeStatus:
+		return s.StatusAlert
+	case sources.ChangePrice:
+		return s.PriceAlert && isPriceAlert(s.PriceBelow, change.OldPrice, change.NewPrice)
+	case sources.ChangeNewPost:
+		return s.BlogAlert && keywords.Match(s.Include, s.Exclude, change.Item.Title+" "+change.Item.Text)
+	case sources.ChangeComments:
+		return s.CommentAlert && keywords.Match(s.Include, s.Exclude, strings.Join(change.Comments, "\n"))
+	case sources.ChangeAnnotation:
+		return s.AnnotationAlert
+	default:
+		return true
+	}
+}
+
+// isPriceAlert сообщает, нужно ли оповещать подписчика с порогом below.
+// Без порога оповещаем о любом изменении, с порогом только о падении
+// цены ниже него.
+func isPriceAlert(below, oldPrice, newPrice int) bool {
+	if below <= 0 {
+		return true
+	}
+
+	return newPrice < below && oldPrice >= below
+}
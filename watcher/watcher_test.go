@@ -0,0 +1,182 @@
+package watcher
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/EfimoffN/authorBot/sources"
+	"github.com/EfimoffN/authorBot/sqlapi"
+)
+
+func Test_wantsChange(t *testing.T) {
+	post := sources.Change{Type: sources.ChangeNewPost, Item: sources.Item{Title: "Новый том", Text: "скоро"}}
+
+	tests := []struct {
+		name   string
+		sub    *sqlapi.SubscriberRow
+		change sources.Change
+		want   bool
+	}{
+		{
+			name:   "blog disabled",
+			sub:    &sqlapi.SubscriberRow{BlogAlert: false},
+			change: post,
+			want:   false,
+		},
+		{
+			name:   "blog with keyword",
+			sub:    &sqlapi.SubscriberRow{BlogAlert: true, Include: "том"},
+			change: post,
+			want:   true,
+		},
+		{
+			name:   "blog without keyword",
+			sub:    &sqlapi.SubscriberRow{BlogAlert: true, Include: "розыгрыш"},
+			change: post,
+			want:   false,
+		},
+		{
+			name:   "comments with keyword",
+			sub:    &sqlapi.SubscriberRow{CommentAlert: true, Include: "розыгрыш"},
+			change: sources.Change{Type: sources.ChangeComments, Count: 2, Item: post.Item, Comments: []string{"Ура!", "Будет Розыгрыш?"}},
+			want:   true,
+		},
+		{
+			name:   "comments without keyword",
+			sub:    &sqlapi.SubscriberRow{CommentAlert: true, Include: "розыгрыш"},
+			change: sources.Change{Type: sources.ChangeComments, Count: 1, Item: post.Item, Comments: []string{"Ура!"}},
+			want:   false,
+		},
+		{
+			name:   "comments with excluded word",
+			sub:    &sqlapi.SubscriberRow{CommentAlert: true, Exclude: "спойлер"},
+			change: sources.Change{Type: sources.ChangeComments, Count: 1, Item: post.Item, Comments: []string{"Осторожно, спойлер"}},
+			want:   false,
+		},
+		{
+			name:   "comments without text",
+			sub:    &sqlapi.SubscriberRow{CommentAlert: true},
+			change: sources.Change{Type: sources.ChangeComments, Count: 1, Item: post.Item},
+			want:   true,
+		},
+		{
+			name:   "annotation disabled",
+			sub:    &sqlapi.SubscriberRow{AnnotationAlert: false},
+			change: sources.Change{Type: sources.ChangeAnnotation},
+			want:   false,
+		},
+		{
+			name:   "chapters ignore keywords",
+			sub:    &sqlapi.SubscriberRow{ChapterAlert: true, Include: "том"},
+			change: sources.Change{Type: sources.ChangeChapters, Title: "книга"},
+			want:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := wantsChange(tt.sub, tt.change); got != tt.want {
+				t.Errorf("wantsChange() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_checkLog(t *testing.T) {
+	now := time.Date(2023, 5, 10, 12, 0, 0, 0, time.UTC)
+
+	l := checkLog{}
+	l.add(now.Add(-90*time.Minute), true)
+	l.add(now.Add(-30*time.Minute), false)
+	l.add(now.Add(-20*time.Minute), true)
+	l.add(now.Add(-time.Minute), false)
+
+	want := CheckStats{Checks: 3, Failures: 1}
+	if got := l.stats(now); got != want {
+		t.Errorf("stats() = %+v, want %+v", got, want)
+	}
+
+	want = CheckStats{Checks: 1}
+	if got := l.stats(now.Add(45 * time.Minute)); got != want {
+		t.Errorf("stats() later = %+v, want %+v", got, want)
+	}
+}
+
+func Test_Alive(t *testing.T) {
+	w := NewWatcher(nil, nil, nil, nil, time.Minute, slog.Default(), context.Background())
+
+	if err := w.Alive(context.Background()); err != nil {
+		t.Errorf("Alive() error = %v, want nil", err)
+	}
+
+	w.heartbeat.Store(time.Now().Add(-time.Minute - heartbeatMargin - time.Second))
+
+	if err := w.Alive(context.Background()); err == nil {
+		t.Errorf("Alive() error = nil, want stale heartbeat error")
+	}
+}
+
+func Test_SetInterval(t *testing.T) {
+	w := NewWatcher(nil, nil, nil, nil, time.Minute, slog.Default(), context.Background())
+
+	w.SetInterval(time.Minute)
+	if len(w.reset) != 0 {
+		t.Error("SetInterval() with the same interval signals reset")
+	}
+
+	w.SetInterval(5 * time.Minute)
+	w.SetInterval(10 * time.Minute)
+
+	if got := w.Interval(); got != 10*time.Minute {
+		t.Errorf("Interval() = %v, want 10m", got)
+	}
+
+	if len(w.reset) != 1 {
+		t.Errorf("reset signals = %d, want 1", len(w.reset))
+	}
+
+	w.SetInterval(0)
+	if got := w.Interval(); got != defaultInterval {
+		t.Errorf("Interval() after SetInterval(0) = %v, want %v", got, defaultInterval)
+	}
+}
+
+// fakeCommentSource отдает комментарии к записям блога из comments.
+type fakeCommentSource struct {
+	sources.Source
+	comments map[string][]string
+}
+
+func (f *fakeCommentSource) Comments(_ context.Context, post sources.Item, count int) ([]string, error) {
+	comments, ok := f.comments[post.ID]
+	if !ok {
+		return nil, errors.New("post not found")
+	}
+
+	return comments[:count], nil
+}
+
+func Test_loadComments(t *testing.T) {
+	w := NewWatcher(nil, nil, nil, nil, time.Minute, slog.New(slog.NewTextHandler(io.Discard, nil)), context.Background())
+	source := &fakeCommentSource{comments: map[string][]string{"1": {"Будет розыгрыш?", "Ура!"}}}
+
+	changes := []sources.Change{
+		{Type: sources.ChangeNewPost, Item: sources.Item{ID: "2"}},
+		{Type: sources.ChangeComments, Count: 1, Item: sources.Item{ID: "1"}},
+		{Type: sources.ChangeComments, Count: 1, Item: sources.Item{ID: "3"}},
+	}
+
+	w.loadComments(w.Log, source, changes)
+
+	want := [][]string{nil, {"Будет розыгрыш?"}, nil}
+	for i, c := range changes {
+		if !reflect.DeepEqual(c.Comments, want[i]) {
+			t.Errorf("changes[%d].Comments = %v, want %v", i, c.Comments, want[i])
+		}
+	}
+}